@@ -0,0 +1,110 @@
+// Package consulcatalog implements a discovery.Provider backed by the
+// Consul service catalog. A service is exposed when its registration
+// carries a "domain" entry in ServiceMeta (an optional "tls" entry marks it
+// HTTPS).
+package consulcatalog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/consul/api"
+
+	"github.com/Gadgitmatic/Dock-Otter/internal/discovery"
+)
+
+// Provider discovers services by querying the Consul catalog.
+type Provider struct {
+	client *api.Client
+}
+
+// New builds a Provider against the Consul agent at addr. An empty addr
+// falls back to the client library's normal defaults (CONSUL_HTTP_ADDR,
+// http://127.0.0.1:8500, ...).
+func New(addr string) (*Provider, error) {
+	cfg := api.DefaultConfig()
+	if addr != "" {
+		cfg.Address = addr
+	}
+
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consul client: %w", err)
+	}
+
+	return &Provider{client: client}, nil
+}
+
+func (p *Provider) Name() string { return "consul" }
+
+func (p *Provider) List(ctx context.Context) ([]discovery.Service, error) {
+	catalog := p.client.Catalog()
+
+	names, _, err := catalog.Services((&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consul services: %w", err)
+	}
+
+	var services []discovery.Service
+	for name := range names {
+		entries, _, err := catalog.Service(name, "", (&api.QueryOptions{}).WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list consul service %s: %w", name, err)
+		}
+
+		// Every entry is a distinct registered instance of the same service
+		// name (the catalog does not filter by health), so they all become
+		// backends of one discovery.Service rather than separate services
+		// competing for the name.
+		var svc *discovery.Service
+		for _, e := range entries {
+			domain := e.ServiceMeta["domain"]
+			if domain == "" {
+				continue
+			}
+
+			port := e.ServicePort
+			if port == 0 {
+				continue
+			}
+
+			tls, _ := strconv.ParseBool(e.ServiceMeta["tls"])
+
+			hostname := e.ServiceAddress
+			if hostname == "" {
+				hostname = e.Address
+			}
+
+			weight, _ := strconv.Atoi(e.ServiceMeta["weight"])
+			if weight == 0 {
+				weight = 1
+			}
+			priority, _ := strconv.Atoi(e.ServiceMeta["priority"])
+			if priority == 0 {
+				priority = 1
+			}
+
+			if svc == nil {
+				svc = &discovery.Service{
+					Name:    name,
+					Domains: []string{domain},
+					TLS:     tls,
+					Labels:  e.ServiceMeta,
+				}
+			}
+			svc.Backends = append(svc.Backends, discovery.Backend{
+				Hostname: hostname,
+				Port:     port,
+				Weight:   weight,
+				Priority: priority,
+			})
+		}
+
+		if svc != nil {
+			services = append(services, *svc)
+		}
+	}
+
+	return services, nil
+}
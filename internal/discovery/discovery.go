@@ -0,0 +1,54 @@
+// Package discovery defines the provider abstraction that Dock Otter uses to
+// find services that should be exposed through Pangolin. Dokploy is the
+// original and still the primary source, but it is no longer the only one:
+// anything that can produce a list of Service values can be plugged in.
+package discovery
+
+import (
+	"context"
+	"time"
+)
+
+// Service is the normalized shape every provider converges on. Dokploy apps,
+// Docker containers, static file entries, and Consul catalog services all
+// get mapped down to this before syncApps ever sees them.
+type Service struct {
+	// Name uniquely identifies the service within a provider and is used as
+	// the dedup key when merging multiple providers together.
+	Name string
+	// Backends is every instance this service currently resolves to - a
+	// compose stack scaled to N replicas or a Consul service with N
+	// registered instances both show up as N backends rather than being
+	// collapsed to one.
+	Backends []Backend
+	Domains  []string
+	TLS      bool
+	Labels   map[string]string
+
+	// HealthCheckPath, when set, is polled by Pangolin to decide whether a
+	// backend is eligible to receive traffic under the "failover" strategy.
+	HealthCheckPath     string
+	HealthCheckInterval time.Duration
+}
+
+// Backend is a single hostname+port a service's traffic can be sent to.
+// Weight and Priority feed the Pangolin load-balancer strategy: higher
+// weight gets more traffic under "random"/"roundrobin", higher priority
+// wins under "failover".
+type Backend struct {
+	Hostname string
+	Port     int
+	Weight   int
+	Priority int
+}
+
+// Provider is implemented by each discovery backend. List returns the full
+// set of services the provider currently knows about; providers are
+// expected to do their own polling/caching and just answer with current
+// state each time List is called.
+type Provider interface {
+	// Name identifies the provider in logs and metrics (e.g. "dokploy",
+	// "docker", "file", "consul").
+	Name() string
+	List(ctx context.Context) ([]Service, error)
+}
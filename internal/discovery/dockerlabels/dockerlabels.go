@@ -0,0 +1,115 @@
+// Package dockerlabels implements a discovery.Provider that reads exposure
+// intent straight from container labels, the same way Traefik/reproxy-style
+// Docker discovery works. It lets workloads that Dokploy never touches
+// (plain `docker run`/compose deployments) still get picked up.
+package dockerlabels
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+
+	"github.com/Gadgitmatic/Dock-Otter/internal/discovery"
+)
+
+// Default label keys, e.g. "dockotter.domain", "dockotter.port", "dockotter.ssl".
+const defaultPrefix = "dockotter"
+
+// Provider discovers services by listing running containers and reading
+// their labels.
+type Provider struct {
+	prefix string
+	client *client.Client
+}
+
+// New connects to the local Docker socket (respecting DOCKER_HOST et al via
+// client.FromEnv). labelPrefix defaults to "dockotter" when empty.
+func New(labelPrefix string) (*Provider, error) {
+	if labelPrefix == "" {
+		labelPrefix = defaultPrefix
+	}
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker client: %w", err)
+	}
+
+	return &Provider{prefix: labelPrefix, client: cli}, nil
+}
+
+func (p *Provider) Name() string { return "docker" }
+
+// List inspects running containers and turns the ones carrying the
+// configured labels into services. Containers without a domain label are
+// ignored - this provider is opt-in per container.
+func (p *Provider) List(ctx context.Context) ([]discovery.Service, error) {
+	containers, err := p.client.ContainerList(ctx, types.ContainerListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	domainKey := p.prefix + ".domain"
+	portKey := p.prefix + ".port"
+	sslKey := p.prefix + ".ssl"
+
+	// Group containers by service name so a compose stack scaled to
+	// multiple replicas - which share com.docker.compose.service but have
+	// distinct container names - is exposed as one service with one
+	// backend per replica, instead of N unrelated services.
+	byName := make(map[string]*discovery.Service)
+	var order []string
+
+	for _, c := range containers {
+		domains, ok := c.Labels[domainKey]
+		if !ok || domains == "" {
+			continue
+		}
+
+		port, err := strconv.Atoi(c.Labels[portKey])
+		if err != nil || port == 0 {
+			continue
+		}
+
+		tls, _ := strconv.ParseBool(c.Labels[sslKey])
+
+		containerName := c.ID[:12]
+		if len(c.Names) > 0 {
+			containerName = strings.TrimPrefix(c.Names[0], "/")
+		}
+
+		name := c.Labels["com.docker.compose.service"]
+		if name == "" {
+			name = containerName
+		}
+
+		svc, exists := byName[name]
+		if !exists {
+			svc = &discovery.Service{
+				Name:    name,
+				Domains: strings.Split(domains, ","),
+				TLS:     tls,
+				Labels:  c.Labels,
+			}
+			byName[name] = svc
+			order = append(order, name)
+		}
+
+		svc.Backends = append(svc.Backends, discovery.Backend{
+			Hostname: containerName,
+			Port:     port,
+			Weight:   1,
+			Priority: 1,
+		})
+	}
+
+	services := make([]discovery.Service, 0, len(order))
+	for _, name := range order {
+		services = append(services, *byName[name])
+	}
+
+	return services, nil
+}
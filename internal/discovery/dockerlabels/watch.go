@@ -0,0 +1,98 @@
+package dockerlabels
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// containerEventActions are the lifecycle transitions worth re-syncing for:
+// a container appearing, disappearing, or being stopped/killed can all
+// change what the docker provider's List would return.
+var containerEventActions = []string{"start", "die", "stop", "kill"}
+
+// Watch listens to the Docker event stream and calls onChange after a burst
+// of relevant container events settles for debounce, so ten events from a
+// compose stack coming up together trigger one sync instead of ten. It
+// blocks until ctx is done or the Docker event stream ends or errors.
+func (p *Provider) Watch(ctx context.Context, debounce time.Duration, onChange func()) {
+	args := filters.NewArgs(
+		filters.Arg("type", string(events.ContainerEventType)),
+	)
+	for _, action := range containerEventActions {
+		args.Add("event", action)
+	}
+
+	evtCh, errs := p.client.Events(ctx, types.EventsOptions{Filters: args})
+
+	// The event/error channels are funneled into a single struct{} signal
+	// channel so the debounce coalescing below - the part worth unit
+	// testing - doesn't need a Docker client to drive it.
+	notify := make(chan struct{})
+	go func() {
+		defer close(notify)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt, ok := <-evtCh:
+				if !ok {
+					return
+				}
+				slog.Debug("Docker event observed", "action", evt.Action, "actor", evt.Actor.ID)
+				select {
+				case notify <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			case err, ok := <-errs:
+				if !ok {
+					return
+				}
+				slog.Error("❌ Docker event stream error", "error", err)
+				return
+			}
+		}
+	}()
+
+	debounceLoop(ctx, notify, debounce, onChange)
+}
+
+// debounceLoop calls onChange once a burst of sends on notify settles for
+// debounce, so a storm of events arriving together (e.g. a compose stack
+// coming up) coalesces into a single call. It returns when ctx is done or
+// notify is closed.
+func debounceLoop(ctx context.Context, notify <-chan struct{}, debounce time.Duration, onChange func()) {
+	var timer *time.Timer
+	var timerC <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case _, ok := <-notify:
+			if !ok {
+				return
+			}
+			if timer == nil {
+				timer = time.NewTimer(debounce)
+			} else {
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(debounce)
+			}
+			timerC = timer.C
+
+		case <-timerC:
+			onChange()
+			timer = nil
+			timerC = nil
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package dockerlabels
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestDebounceLoop_CoalescesBurst verifies that a burst of sends on notify,
+// arriving faster than the debounce window, triggers onChange exactly once.
+func TestDebounceLoop_CoalescesBurst(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notify := make(chan struct{})
+	var calls int32
+	done := make(chan struct{})
+	go func() {
+		debounceLoop(ctx, notify, 30*time.Millisecond, func() { atomic.AddInt32(&calls, 1) })
+		close(done)
+	}()
+
+	for i := 0; i < 5; i++ {
+		notify <- struct{}{}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("onChange called %d times, want 1", got)
+	}
+
+	cancel()
+	<-done
+}
+
+// TestDebounceLoop_SeparateBurstsFireSeparately verifies that two bursts
+// spaced further apart than the debounce window each trigger their own call.
+func TestDebounceLoop_SeparateBurstsFireSeparately(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notify := make(chan struct{})
+	var calls int32
+	done := make(chan struct{})
+	go func() {
+		debounceLoop(ctx, notify, 20*time.Millisecond, func() { atomic.AddInt32(&calls, 1) })
+		close(done)
+	}()
+
+	notify <- struct{}{}
+	time.Sleep(50 * time.Millisecond)
+	notify <- struct{}{}
+	time.Sleep(50 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("onChange called %d times, want 2", got)
+	}
+
+	cancel()
+	<-done
+}
+
+// TestDebounceLoop_StopsOnClosedChannel verifies the loop returns when
+// notify is closed, mirroring the event stream ending.
+func TestDebounceLoop_StopsOnClosedChannel(t *testing.T) {
+	notify := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		debounceLoop(context.Background(), notify, 10*time.Millisecond, func() {})
+		close(done)
+	}()
+
+	close(notify)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("debounceLoop did not return after notify closed")
+	}
+}
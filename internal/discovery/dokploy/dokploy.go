@@ -0,0 +1,261 @@
+// Package dokploy implements a discovery.Provider backed by the Dokploy
+// REST API. This is the original and default Dock Otter source.
+package dokploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/Gadgitmatic/Dock-Otter/internal/discovery"
+	"github.com/Gadgitmatic/Dock-Otter/internal/errdefs"
+	"github.com/Gadgitmatic/Dock-Otter/internal/metrics"
+)
+
+// Config holds the Dokploy connection settings needed to build a Provider.
+type Config struct {
+	URL     string
+	APIKey  string
+	Token   string
+	Session string
+}
+
+// Provider discovers services by polling the Dokploy projects API.
+type Provider struct {
+	cfg    Config
+	client *resty.Client
+}
+
+// New builds a Dokploy provider with a resty client configured the same way
+// the rest of Dock Otter talks to external APIs (timeouts, retries, auth).
+func New(cfg Config) *Provider {
+	client := resty.New().
+		SetTimeout(30*time.Second).
+		SetRetryCount(2).
+		SetRetryWaitTime(1*time.Second).
+		SetRetryMaxWaitTime(5*time.Second).
+		SetHeader("User-Agent", "dock-otter/1.0").
+		SetHeader("Accept", "application/json")
+
+	if cfg.APIKey != "" {
+		client.SetHeader("X-API-Key", cfg.APIKey)
+	}
+	if cfg.Token != "" {
+		client.SetHeader("Authorization", "Bearer "+cfg.Token)
+	}
+	if cfg.Session != "" {
+		client.SetHeader("Cookie", "session="+cfg.Session)
+	}
+
+	metrics.Instrument(client, "dokploy")
+
+	return &Provider{cfg: cfg, client: client}
+}
+
+func (p *Provider) Name() string { return "dokploy" }
+
+// AuthType reports which credential Dock Otter is using, for startup logs.
+func (p *Provider) AuthType() string {
+	switch {
+	case p.cfg.APIKey != "":
+		return "API key"
+	case p.cfg.Token != "":
+		return "Bearer token"
+	case p.cfg.Session != "":
+		return "Session cookie"
+	default:
+		return "none"
+	}
+}
+
+// List fetches all projects from Dokploy and flattens their applications and
+// compose stacks into normalized services, one per app - matching every
+// other provider, which also emit one Service carrying every domain rather
+// than one Service per domain.
+func (p *Provider) List(ctx context.Context) ([]discovery.Service, error) {
+	projects, err := p.getProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	var services []discovery.Service
+	for _, project := range projects {
+		apps := append(append([]App{}, project.Applications...), project.Compose...)
+		for _, app := range apps {
+			if app.Status != "done" {
+				slog.Debug("Skipping app - not running", "app", app.Name, "status", app.Status)
+				continue
+			}
+			if len(app.Domains) == 0 {
+				slog.Debug("Skipping app - no domains", "app", app.Name)
+				continue
+			}
+
+			svc, ok := buildAppService(app, project.ProjectID)
+			if !ok {
+				slog.Warn("Skipping app - no domain has a resolvable port", "app", app.Name)
+				continue
+			}
+			services = append(services, svc)
+		}
+	}
+
+	return services, nil
+}
+
+// buildAppService folds every domain of app into a single discovery.Service
+// rather than one Service per domain - the shape the rest of Dock Otter
+// (buildDesiredResources's dedup, the prune pass) expects, and the shape
+// every other provider already produces. Port, TLS, and path can in theory
+// be pinned per-domain in Dokploy, but discovery.Service only has room for
+// one set of Backends per service, so they're resolved once from the first
+// domain with a usable port; every domain with a resolvable port is still
+// exposed, just sharing that port/TLS/path rather than its own.
+func buildAppService(app App, projectID string) (discovery.Service, bool) {
+	var domains []string
+	var port int
+	var tls bool
+	var path string
+
+	for _, domain := range app.Domains {
+		resolved := resolvePort(app, domain)
+		if resolved == 0 {
+			slog.Warn("Skipping domain - no port available", "app", app.Name, "domain", domain.Host)
+			continue
+		}
+		if len(domains) == 0 {
+			port, tls, path = resolved, domain.HTTPS, domain.Path
+		}
+		domains = append(domains, domain.Host)
+	}
+	if len(domains) == 0 {
+		return discovery.Service{}, false
+	}
+
+	return discovery.Service{
+		Name:     app.Name,
+		Backends: backendsForApp(app, port),
+		Domains:  domains,
+		TLS:      tls,
+		Labels: map[string]string{
+			"source":    "dokploy",
+			"projectId": projectID,
+			"path":      path,
+		},
+	}, true
+}
+
+// backendsForApp expands a compose stack scaled to N replicas into one
+// Backend per replica. Dokploy runs scaled compose stacks as Docker Swarm
+// services, and Swarm assigns each replica's container the deterministic
+// name "<appName>.<n>" (1-indexed) inside the overlay network, so those
+// names are resolvable without any extra Dokploy API/DNS lookup. Regular
+// applications and un-scaled compose stacks are never split across
+// replicas, so they still resolve through the single AppName hostname.
+func backendsForApp(app App, port int) []discovery.Backend {
+	if app.ComposeID == "" || app.Replicas <= 1 {
+		return []discovery.Backend{{Hostname: app.AppName, Port: port, Weight: 1, Priority: 1}}
+	}
+
+	backends := make([]discovery.Backend, 0, app.Replicas)
+	for i := 1; i <= app.Replicas; i++ {
+		backends = append(backends, discovery.Backend{
+			Hostname: fmt.Sprintf("%s.%d", app.AppName, i),
+			Port:     port,
+			Weight:   1,
+			Priority: 1,
+		})
+	}
+	return backends
+}
+
+// resolvePort mirrors the original port-resolution priority: a domain can
+// pin its own port, otherwise fall back to the app's port, otherwise a
+// protocol default.
+func resolvePort(app App, domain Domain) int {
+	if domain.Port > 0 {
+		return domain.Port
+	}
+	if app.Port > 0 {
+		return app.Port
+	}
+	if domain.HTTPS {
+		return 443
+	}
+	return 80
+}
+
+func (p *Provider) getProjects(ctx context.Context) ([]Project, error) {
+	// Try multiple endpoints for different Dokploy versions.
+	endpoints := []string{
+		"/api/projects",
+		"/api/project/all",
+		"/api/project",
+		"/api/applications",
+	}
+
+	var lastErr error
+	for _, endpoint := range endpoints {
+		resp, err := p.client.R().
+			SetContext(ctx).
+			SetHeader("Accept", "application/json").
+			Get(p.cfg.URL + endpoint)
+
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if resp.StatusCode() == 200 {
+			var projects []Project
+			if err := json.Unmarshal(resp.Body(), &projects); err != nil {
+				lastErr = err
+				continue
+			}
+			slog.Info("✅ Found working Dokploy endpoint", "endpoint", endpoint)
+			return projects, nil
+		}
+
+		lastErr = fmt.Errorf("endpoint %s: %w", endpoint, errdefs.FromHTTPStatus(resp.StatusCode(), resp.String(), resp.Header()))
+	}
+
+	return nil, fmt.Errorf("all endpoints failed, last error: %w", lastErr)
+}
+
+// Dokploy API types (based on actual API structure).
+type Project struct {
+	ProjectID    string `json:"projectId"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Applications []App  `json:"applications"`
+	Compose      []App  `json:"compose"`
+}
+
+type App struct {
+	ApplicationID string   `json:"applicationId,omitempty"`
+	ComposeID     string   `json:"composeId,omitempty"`
+	Name          string   `json:"name"`
+	AppName       string   `json:"appName"`
+	Description   string   `json:"description"`
+	Domains       []Domain `json:"domains,omitempty"`
+	Port          int      `json:"port,omitempty"`
+	Status        string   `json:"applicationStatus"`
+	ProjectID     string   `json:"projectId"`
+	// Replicas is only populated for Compose entries running in Docker
+	// Swarm "stack" mode; Dokploy omits it entirely for plain Applications
+	// and for compose stacks that aren't scaled, both of which leave it 0.
+	Replicas int `json:"replicas,omitempty"`
+}
+
+type Domain struct {
+	DomainID    string `json:"domainId"`
+	Host        string `json:"host"`
+	Path        string `json:"path"`
+	Port        int    `json:"port"`
+	HTTPS       bool   `json:"https"`
+	Certificate string `json:"certificate,omitempty"`
+}
@@ -0,0 +1,76 @@
+package dokploy
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestBuildAppService_MultiDomain(t *testing.T) {
+	app := App{
+		Name:    "web",
+		AppName: "web-app",
+		Status:  "done",
+		Port:    3000,
+		Domains: []Domain{
+			{Host: "example.com", Path: "/", HTTPS: true},
+			{Host: "www.example.com", Path: "/", HTTPS: true},
+		},
+	}
+
+	svc, ok := buildAppService(app, "proj-1")
+	if !ok {
+		t.Fatal("buildAppService() ok = false, want true")
+	}
+
+	if svc.Name != "web" {
+		t.Errorf("Name = %q, want %q", svc.Name, "web")
+	}
+
+	gotDomains := append([]string{}, svc.Domains...)
+	sort.Strings(gotDomains)
+	wantDomains := []string{"example.com", "www.example.com"}
+	if !reflect.DeepEqual(gotDomains, wantDomains) {
+		t.Errorf("Domains = %v, want %v (both domains of a multi-domain app must survive)", gotDomains, wantDomains)
+	}
+
+	if len(svc.Backends) != 1 || svc.Backends[0].Hostname != "web-app" || svc.Backends[0].Port != 3000 {
+		t.Errorf("Backends = %+v, want one backend at web-app:3000", svc.Backends)
+	}
+}
+
+// TestBuildAppService_PortResolvedFromFirstDomain documents the one
+// port/TLS/path-for-the-whole-service tradeoff: a domain that pins its own
+// port still gets exposed, but shares whichever domain came first's
+// resolved port rather than keeping its own, since discovery.Service has
+// room for only one set of Backends.
+func TestBuildAppService_PortResolvedFromFirstDomain(t *testing.T) {
+	app := App{
+		Name:    "web",
+		AppName: "web-app",
+		Status:  "done",
+		Domains: []Domain{
+			{Host: "example.com", Port: 8080},
+			{Host: "other.example.com", Port: 9090},
+		},
+	}
+
+	svc, ok := buildAppService(app, "proj-1")
+	if !ok {
+		t.Fatal("buildAppService() ok = false, want true")
+	}
+	if len(svc.Domains) != 2 {
+		t.Errorf("Domains = %v, want both domains present", svc.Domains)
+	}
+	if svc.Backends[0].Port != 8080 {
+		t.Errorf("Backends[0].Port = %d, want 8080 (first domain's resolved port)", svc.Backends[0].Port)
+	}
+}
+
+func TestBuildAppService_NoDomains(t *testing.T) {
+	app := App{Name: "web", AppName: "web-app", Status: "done"}
+
+	if _, ok := buildAppService(app, "proj-1"); ok {
+		t.Error("buildAppService() ok = true, want false when the app has no domains")
+	}
+}
@@ -0,0 +1,122 @@
+// Package staticfile implements a discovery.Provider backed by a YAML file
+// of hand-written service entries, for workloads that aren't discoverable
+// any other way (bare-metal processes, external systems, etc).
+package staticfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gadgitmatic/Dock-Otter/internal/discovery"
+)
+
+// Provider re-reads path on every List call, so editing the file is enough
+// to change what gets synced - no restart required.
+type Provider struct {
+	path string
+}
+
+// New returns a provider that loads services from the YAML file at path.
+func New(path string) *Provider {
+	return &Provider{path: path}
+}
+
+func (p *Provider) Name() string { return "file" }
+
+type fileConfig struct {
+	Services []fileService `yaml:"services"`
+}
+
+type fileService struct {
+	Name    string            `yaml:"name"`
+	Domains []string          `yaml:"domains"`
+	TLS     bool              `yaml:"tls"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+
+	// Backends lists every instance to load-balance across. Single-backend
+	// services may use the hostname/port shorthand instead.
+	Backends []fileBackend `yaml:"backends,omitempty"`
+	Hostname string        `yaml:"hostname,omitempty"`
+	Port     int           `yaml:"port,omitempty"`
+
+	HealthCheckPath     string `yaml:"healthCheckPath,omitempty"`
+	HealthCheckInterval string `yaml:"healthCheckInterval,omitempty"`
+}
+
+type fileBackend struct {
+	Hostname string `yaml:"hostname"`
+	Port     int    `yaml:"port"`
+	Weight   int    `yaml:"weight,omitempty"`
+	Priority int    `yaml:"priority,omitempty"`
+}
+
+func (p *Provider) List(ctx context.Context) ([]discovery.Service, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read static services file %s: %w", p.path, err)
+	}
+
+	var cfg fileConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse static services file %s: %w", p.path, err)
+	}
+
+	services := make([]discovery.Service, 0, len(cfg.Services))
+	for _, s := range cfg.Services {
+		if len(s.Domains) == 0 {
+			continue
+		}
+
+		backends := make([]discovery.Backend, 0, len(s.Backends)+1)
+		for _, b := range s.Backends {
+			weight, priority := b.Weight, b.Priority
+			if weight == 0 {
+				weight = 1
+			}
+			if priority == 0 {
+				priority = 1
+			}
+			backends = append(backends, discovery.Backend{
+				Hostname: b.Hostname,
+				Port:     b.Port,
+				Weight:   weight,
+				Priority: priority,
+			})
+		}
+		if s.Hostname != "" && s.Port != 0 {
+			backends = append(backends, discovery.Backend{
+				Hostname: s.Hostname,
+				Port:     s.Port,
+				Weight:   1,
+				Priority: 1,
+			})
+		}
+		if len(backends) == 0 {
+			continue
+		}
+
+		var healthCheckInterval time.Duration
+		if s.HealthCheckInterval != "" {
+			healthCheckInterval, err = time.ParseDuration(s.HealthCheckInterval)
+			if err != nil {
+				return nil, fmt.Errorf("service %s: invalid healthCheckInterval %q: %w", s.Name, s.HealthCheckInterval, err)
+			}
+		}
+
+		services = append(services, discovery.Service{
+			Name:                s.Name,
+			Backends:            backends,
+			Domains:             s.Domains,
+			TLS:                 s.TLS,
+			Labels:              s.Labels,
+			HealthCheckPath:     s.HealthCheckPath,
+			HealthCheckInterval: healthCheckInterval,
+		})
+	}
+
+	return services, nil
+}
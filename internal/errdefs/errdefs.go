@@ -0,0 +1,131 @@
+// Package errdefs defines the error kinds Dock Otter's Dokploy and Pangolin
+// clients classify HTTP responses into, modeled on moby's api/errdefs: a
+// small set of interfaces callers type-assert against instead of matching
+// on error strings or status codes at every call site.
+package errdefs
+
+import "errors"
+
+// Kind identifies which of the handful of ways an API call can fail.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindNotFound
+	KindUnauthorized
+	KindConflict
+	KindRateLimited
+	KindUnavailable
+	KindInvalid
+)
+
+// String names a Kind the way it should appear in metric labels and logs.
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not_found"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindConflict:
+		return "conflict"
+	case KindRateLimited:
+		return "rate_limited"
+	case KindUnavailable:
+		return "unavailable"
+	case KindInvalid:
+		return "invalid"
+	default:
+		return "unknown"
+	}
+}
+
+type errNotFound interface{ NotFound() bool }
+type errUnauthorized interface{ Unauthorized() bool }
+type errConflict interface{ Conflict() bool }
+type errRateLimited interface{ RateLimited() bool }
+type errUnavailable interface{ Unavailable() bool }
+type errInvalid interface{ Invalid() bool }
+
+// IsNotFound reports whether err (or something it wraps) is a not-found error.
+func IsNotFound(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(errNotFound); ok && e.NotFound() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUnauthorized reports whether err is an authentication/authorization failure.
+func IsUnauthorized(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(errUnauthorized); ok && e.Unauthorized() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsConflict reports whether err is a conflicting-state failure.
+func IsConflict(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(errConflict); ok && e.Conflict() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRateLimited reports whether err is a rate-limit (HTTP 429) failure.
+func IsRateLimited(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(errRateLimited); ok && e.RateLimited() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUnavailable reports whether err is a transient server-side failure worth
+// retrying (HTTP 5xx). Transport failures such as a refused connection are
+// not classified by this package and do not match.
+func IsUnavailable(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(errUnavailable); ok && e.Unavailable() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInvalid reports whether err is a client-side/validation failure that
+// retrying would never fix (HTTP 400/422).
+func IsInvalid(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(errInvalid); ok && e.Invalid() {
+			return true
+		}
+	}
+	return false
+}
+
+// KindOf classifies err into a Kind for metrics/logging, defaulting to
+// KindUnknown when it isn't one of ours.
+func KindOf(err error) Kind {
+	switch {
+	case IsNotFound(err):
+		return KindNotFound
+	case IsUnauthorized(err):
+		return KindUnauthorized
+	case IsConflict(err):
+		return KindConflict
+	case IsRateLimited(err):
+		return KindRateLimited
+	case IsUnavailable(err):
+		return KindUnavailable
+	case IsInvalid(err):
+		return KindInvalid
+	default:
+		return KindUnknown
+	}
+}
@@ -0,0 +1,79 @@
+package errdefs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// httpError classifies a non-2xx HTTP response into a Kind so callers can
+// branch on errdefs.IsX(err) instead of matching status codes themselves.
+type httpError struct {
+	kind       Kind
+	status     int
+	body       string
+	retryAfter time.Duration
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.status, e.body)
+}
+
+func (e *httpError) NotFound() bool     { return e.kind == KindNotFound }
+func (e *httpError) Unauthorized() bool { return e.kind == KindUnauthorized }
+func (e *httpError) Conflict() bool     { return e.kind == KindConflict }
+func (e *httpError) RateLimited() bool  { return e.kind == KindRateLimited }
+func (e *httpError) Unavailable() bool  { return e.kind == KindUnavailable }
+func (e *httpError) Invalid() bool      { return e.kind == KindInvalid }
+
+// FromHTTPStatus builds an error classified by HTTP status code. header may
+// be nil; when the response carries a Retry-After header given as a positive
+// number of seconds, RetryAfter(err) returns it. The HTTP-date form is not
+// parsed.
+func FromHTTPStatus(status int, body string, header http.Header) error {
+	kind := classifyStatus(status)
+
+	var retryAfter time.Duration
+	if header != nil {
+		if v := header.Get("Retry-After"); v != "" {
+			if secs, err := strconv.Atoi(v); err == nil {
+				retryAfter = time.Duration(secs) * time.Second
+			}
+		}
+	}
+
+	return &httpError{kind: kind, status: status, body: body, retryAfter: retryAfter}
+}
+
+func classifyStatus(status int) Kind {
+	switch {
+	case status == http.StatusNotFound:
+		return KindNotFound
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return KindUnauthorized
+	case status == http.StatusConflict:
+		return KindConflict
+	case status == http.StatusTooManyRequests:
+		return KindRateLimited
+	case status == http.StatusBadRequest || status == http.StatusUnprocessableEntity:
+		return KindInvalid
+	case status >= 500:
+		return KindUnavailable
+	default:
+		return KindUnknown
+	}
+}
+
+// RetryAfter returns the server-requested backoff carried by err, if any,
+// unwrapping err the same way the IsX helpers do so a wrapped httpError
+// (e.g. via fmt.Errorf("...: %w", err)) is still found.
+func RetryAfter(err error) (time.Duration, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(*httpError); ok && e.retryAfter > 0 {
+			return e.retryAfter, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,81 @@
+package errdefs
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClassifyStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   Kind
+	}{
+		{http.StatusNotFound, KindNotFound},
+		{http.StatusUnauthorized, KindUnauthorized},
+		{http.StatusForbidden, KindUnauthorized},
+		{http.StatusConflict, KindConflict},
+		{http.StatusTooManyRequests, KindRateLimited},
+		{http.StatusBadRequest, KindInvalid},
+		{http.StatusUnprocessableEntity, KindInvalid},
+		{http.StatusInternalServerError, KindUnavailable},
+		{http.StatusBadGateway, KindUnavailable},
+		{http.StatusTeapot, KindUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := classifyStatus(tt.status); got != tt.want {
+			t.Errorf("classifyStatus(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Kind
+	}{
+		{"not found", FromHTTPStatus(http.StatusNotFound, "", nil), KindNotFound},
+		{"rate limited", FromHTTPStatus(http.StatusTooManyRequests, "", nil), KindRateLimited},
+		{"wrapped unavailable", fmt.Errorf("call failed: %w", FromHTTPStatus(http.StatusBadGateway, "", nil)), KindUnavailable},
+		{"not one of ours", fmt.Errorf("boom"), KindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KindOf(tt.err); got != tt.want {
+				t.Errorf("KindOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "5")
+	withRetry := FromHTTPStatus(http.StatusTooManyRequests, "", header)
+
+	t.Run("direct error", func(t *testing.T) {
+		d, ok := RetryAfter(withRetry)
+		if !ok || d != 5*time.Second {
+			t.Errorf("RetryAfter() = %v, %v, want 5s, true", d, ok)
+		}
+	})
+
+	t.Run("wrapped error", func(t *testing.T) {
+		wrapped := fmt.Errorf("get projects: %w", withRetry)
+		d, ok := RetryAfter(wrapped)
+		if !ok || d != 5*time.Second {
+			t.Errorf("RetryAfter() = %v, %v, want 5s, true", d, ok)
+		}
+	})
+
+	t.Run("no Retry-After header", func(t *testing.T) {
+		noHeader := FromHTTPStatus(http.StatusTooManyRequests, "", nil)
+		if _, ok := RetryAfter(noHeader); ok {
+			t.Errorf("RetryAfter() ok = true, want false")
+		}
+	})
+}
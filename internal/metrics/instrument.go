@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Gadgitmatic/Dock-Otter/internal/tracing"
+)
+
+type startTimeKey struct{}
+type spanKey struct{}
+
+// Instrument wires OnBeforeRequest/OnAfterResponse/OnError hooks onto
+// client that record APIRequestsTotal and APIRequestDuration, and open an
+// OTel client span, for every call it makes - labeled/named with service
+// (e.g. "pangolin", "dokploy") and the request's method+path as the
+// endpoint.
+func Instrument(client *resty.Client, service string) {
+	client.OnBeforeRequest(func(c *resty.Client, r *resty.Request) error {
+		ctx, span := tracing.Tracer.Start(r.Context(), service+" "+r.Method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.url", r.URL),
+			))
+		ctx = context.WithValue(ctx, startTimeKey{}, time.Now())
+		ctx = context.WithValue(ctx, spanKey{}, span)
+		r.SetContext(ctx)
+		return nil
+	})
+
+	client.OnAfterResponse(func(c *resty.Client, r *resty.Response) error {
+		endpoint := routeLabel(r.Request.Method, r.Request.URL)
+		status := strconv.Itoa(r.StatusCode())
+		APIRequestsTotal.WithLabelValues(service, endpoint, status).Inc()
+		if start, ok := r.Request.Context().Value(startTimeKey{}).(time.Time); ok {
+			APIRequestDuration.WithLabelValues(service, endpoint).Observe(time.Since(start).Seconds())
+		}
+		if span, ok := r.Request.Context().Value(spanKey{}).(trace.Span); ok {
+			span.SetAttributes(attribute.Int("http.status_code", r.StatusCode()))
+			if r.IsError() {
+				span.SetStatus(codes.Error, r.Status())
+			}
+			span.End()
+		}
+		return nil
+	})
+
+	client.OnError(func(r *resty.Request, err error) {
+		endpoint := routeLabel(r.Method, r.URL)
+		APIRequestsTotal.WithLabelValues(service, endpoint, "error").Inc()
+		if start, ok := r.Context().Value(startTimeKey{}).(time.Time); ok {
+			APIRequestDuration.WithLabelValues(service, endpoint).Observe(time.Since(start).Seconds())
+		}
+		if span, ok := r.Context().Value(spanKey{}).(trace.Span); ok {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			span.End()
+		}
+	})
+}
+
+// routeLabel collapses rawURL down to a low-cardinality "METHOD /route"
+// label: scheme and host are dropped, and any path segment past the
+// resource collection name (e.g. the blueprint/resource name in
+// /v1/blueprints/<name>) is replaced with ":name" so per-resource calls
+// share one label value instead of minting a new time series each.
+func routeLabel(method, rawURL string) string {
+	path := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		path = u.Path
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 2; i < len(segments); i++ {
+		segments[i] = ":name"
+	}
+	return method + " /" + strings.Join(segments, "/")
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Gadgitmatic/Dock-Otter/internal/pangolin"
+)
+
+// HeadersMiddleware sets and drops the given headers on every resource in
+// the blueprint, the same way reproxy's ProxyHeaders/DropHeaders work.
+func HeadersMiddleware(set map[string]string, drop []string) Middleware {
+	return func(ctx context.Context, bp *pangolin.Blueprint) (*pangolin.Blueprint, error) {
+		for i := range bp.ProxyResources {
+			bp.ProxyResources[i].Headers = &pangolin.Headers{Set: set, Drop: drop}
+		}
+		return bp, nil
+	}
+}
+
+// BasicAuthMiddleware gates every resource behind HTTP basic auth, checked
+// against an htpasswd file. It only verifies the file exists and is
+// readable; Pangolin itself does the credential check at request time.
+func BasicAuthMiddleware(htpasswdFile string) (Middleware, error) {
+	f, err := os.Open(htpasswdFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open htpasswd file %s: %w", htpasswdFile, err)
+	}
+	f.Close()
+
+	return func(ctx context.Context, bp *pangolin.Blueprint) (*pangolin.Blueprint, error) {
+		for i := range bp.ProxyResources {
+			bp.ProxyResources[i].BasicAuth = &pangolin.BasicAuth{HtpasswdFile: htpasswdFile}
+		}
+		return bp, nil
+	}, nil
+}
+
+// PathRewriteRule rewrites any target path matching Match to Replace.
+type PathRewriteRule struct {
+	Match   string
+	Replace string
+}
+
+// PathRewriteMiddleware applies rules in order to every target's path,
+// first match wins per target.
+func PathRewriteMiddleware(rules []PathRewriteRule) Middleware {
+	return func(ctx context.Context, bp *pangolin.Blueprint) (*pangolin.Blueprint, error) {
+		for i := range bp.ProxyResources {
+			targets := bp.ProxyResources[i].Targets
+			for j := range targets {
+				for _, rule := range rules {
+					if strings.HasPrefix(targets[j].Path, rule.Match) {
+						targets[j].Path = rule.Replace + strings.TrimPrefix(targets[j].Path, rule.Match)
+						break
+					}
+				}
+			}
+		}
+		return bp, nil
+	}
+}
+
+// RateLimitMiddleware caps requests per second (with burst) on every
+// resource's domain.
+func RateLimitMiddleware(requestsPerSecond, burst int) Middleware {
+	return func(ctx context.Context, bp *pangolin.Blueprint) (*pangolin.Blueprint, error) {
+		for i := range bp.ProxyResources {
+			bp.ProxyResources[i].RateLimit = &pangolin.RateLimit{
+				RequestsPerSecond: requestsPerSecond,
+				Burst:             burst,
+			}
+		}
+		return bp, nil
+	}
+}
+
+// IPFilterMiddleware allow/deny-lists client IPs and CIDRs on every
+// resource.
+func IPFilterMiddleware(allow, deny []string) Middleware {
+	return func(ctx context.Context, bp *pangolin.Blueprint) (*pangolin.Blueprint, error) {
+		for i := range bp.ProxyResources {
+			bp.ProxyResources[i].IPFilter = &pangolin.IPFilter{Allow: allow, Deny: deny}
+		}
+		return bp, nil
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileConfig is the shape of the YAML file pointed to by MIDDLEWARE_CONFIG.
+// Every section is optional; omitted sections build no middleware.
+type FileConfig struct {
+	Middlewares Config `yaml:"middlewares"`
+}
+
+type Config struct {
+	Headers      *HeadersConfig     `yaml:"headers,omitempty"`
+	BasicAuth    *BasicAuthConfig   `yaml:"basic_auth,omitempty"`
+	PathRewrites []PathRewriteEntry `yaml:"path_rewrites,omitempty"`
+	RateLimit    *RateLimitConfig   `yaml:"rate_limit,omitempty"`
+	IPFilter     *IPFilterConfig    `yaml:"ip_filter,omitempty"`
+	Plugin       *PluginConfig      `yaml:"plugin,omitempty"`
+}
+
+type HeadersConfig struct {
+	Set  map[string]string `yaml:"set,omitempty"`
+	Drop []string          `yaml:"drop,omitempty"`
+}
+
+type BasicAuthConfig struct {
+	HtpasswdFile string `yaml:"htpasswd_file"`
+}
+
+type PathRewriteEntry struct {
+	Match   string `yaml:"match"`
+	Replace string `yaml:"replace"`
+}
+
+type RateLimitConfig struct {
+	RequestsPerSecond int `yaml:"requests_per_second"`
+	Burst             int `yaml:"burst,omitempty"`
+}
+
+type IPFilterConfig struct {
+	Allow []string `yaml:"allow,omitempty"`
+	Deny  []string `yaml:"deny,omitempty"`
+}
+
+type PluginConfig struct {
+	Addr string `yaml:"addr"`
+}
+
+// LoadFile reads and parses the middleware config file at path.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read middleware config %s: %w", path, err)
+	}
+
+	var file FileConfig
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse middleware config %s: %w", path, err)
+	}
+	return &file.Middlewares, nil
+}
+
+// Build turns the parsed config into a Chain, in a fixed, predictable
+// order: headers, basic auth, path rewrites, rate limit, IP filter, then
+// the out-of-process plugin last so it sees every built-in transform's
+// output.
+func (c *Config) Build() (*Chain, error) {
+	if c == nil {
+		return NewChain(), nil
+	}
+
+	var mws []Middleware
+
+	if c.Headers != nil {
+		mws = append(mws, HeadersMiddleware(c.Headers.Set, c.Headers.Drop))
+	}
+
+	if c.BasicAuth != nil {
+		mw, err := BasicAuthMiddleware(c.BasicAuth.HtpasswdFile)
+		if err != nil {
+			return nil, err
+		}
+		mws = append(mws, mw)
+	}
+
+	if len(c.PathRewrites) > 0 {
+		rules := make([]PathRewriteRule, 0, len(c.PathRewrites))
+		for _, r := range c.PathRewrites {
+			rules = append(rules, PathRewriteRule{Match: r.Match, Replace: r.Replace})
+		}
+		mws = append(mws, PathRewriteMiddleware(rules))
+	}
+
+	if c.RateLimit != nil {
+		mws = append(mws, RateLimitMiddleware(c.RateLimit.RequestsPerSecond, c.RateLimit.Burst))
+	}
+
+	if c.IPFilter != nil {
+		mws = append(mws, IPFilterMiddleware(c.IPFilter.Allow, c.IPFilter.Deny))
+	}
+
+	if c.Plugin != nil && c.Plugin.Addr != "" {
+		mws = append(mws, PluginMiddleware(c.Plugin.Addr))
+	}
+
+	return NewChain(mws...), nil
+}
@@ -0,0 +1,50 @@
+// Package middleware lets operators bolt site-specific policy onto the
+// blueprints Dock Otter builds - header rewriting, auth, rate limits, IP
+// filtering, and out-of-process plugins - without recompiling Dock Otter
+// itself.
+package middleware
+
+import (
+	"context"
+
+	"github.com/Gadgitmatic/Dock-Otter/internal/pangolin"
+)
+
+// Middleware transforms a blueprint after it's been built from discovered
+// services and before it's pushed to Pangolin. It may mutate bp in place or
+// return a new one; returning an error aborts the sync for that resource.
+type Middleware func(ctx context.Context, bp *pangolin.Blueprint) (*pangolin.Blueprint, error)
+
+// Chain runs a fixed list of middlewares in order, each seeing the previous
+// one's output. A nil or empty Chain is a no-op passthrough.
+type Chain struct {
+	middlewares []Middleware
+}
+
+// NewChain builds a Chain that runs mw in the given order.
+func NewChain(mw ...Middleware) *Chain {
+	return &Chain{middlewares: mw}
+}
+
+// Append returns a new Chain that runs c's middlewares followed by mw.
+func (c *Chain) Append(mw ...Middleware) *Chain {
+	if c == nil {
+		return NewChain(mw...)
+	}
+	return NewChain(append(append([]Middleware{}, c.middlewares...), mw...)...)
+}
+
+// Apply runs every middleware in the chain over bp, in order.
+func (c *Chain) Apply(ctx context.Context, bp *pangolin.Blueprint) (*pangolin.Blueprint, error) {
+	if c == nil {
+		return bp, nil
+	}
+	var err error
+	for _, mw := range c.middlewares {
+		bp, err = mw(ctx, bp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bp, nil
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/rpc"
+
+	"github.com/Gadgitmatic/Dock-Otter/internal/pangolin"
+)
+
+// PluginRequest/PluginResponse are the net/rpc payload exchanged with an
+// out-of-process plugin. Every field must stay exported so net/rpc's gob
+// encoding can see it.
+type PluginRequest struct {
+	Blueprint *pangolin.Blueprint
+}
+
+type PluginResponse struct {
+	Blueprint *pangolin.Blueprint
+}
+
+// PluginMiddleware hands the blueprint to an out-of-process plugin over
+// net/rpc on a Unix socket, so operators can add site-specific transforms
+// without recompiling Dock Otter. The plugin must expose a "Plugin.Transform"
+// RPC method with the PluginRequest/PluginResponse signature.
+func PluginMiddleware(addr string) Middleware {
+	return func(ctx context.Context, bp *pangolin.Blueprint) (*pangolin.Blueprint, error) {
+		client, err := rpc.Dial("unix", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial plugin at %s: %w", addr, err)
+		}
+		defer client.Close()
+
+		resp := new(PluginResponse)
+		call := client.Go("Plugin.Transform", &PluginRequest{Blueprint: bp}, resp, nil)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case result := <-call.Done:
+			if result.Error != nil {
+				return nil, fmt.Errorf("plugin transform failed: %w", result.Error)
+			}
+		}
+
+		if resp.Blueprint == nil {
+			return bp, nil
+		}
+		return resp.Blueprint, nil
+	}
+}
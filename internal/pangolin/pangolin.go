@@ -0,0 +1,257 @@
+// Package pangolin is a thin client for the subset of the Pangolin API that
+// Dock Otter drives: pushing blueprints and listing/removing the resources
+// it has previously created.
+package pangolin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Gadgitmatic/Dock-Otter/internal/errdefs"
+	"github.com/Gadgitmatic/Dock-Otter/internal/metrics"
+)
+
+// ManagedByDockOtter is stamped onto every resource Dock Otter creates so a
+// later reconcile pass can tell its own resources apart from ones a human
+// authored directly in Pangolin, and never deletes the latter.
+const ManagedByDockOtter = "dock-otter"
+
+// Blueprint is the YAML document posted to the Pangolin blueprints API.
+type Blueprint struct {
+	ProxyResources []ProxyResource `yaml:"proxy-resources"`
+}
+
+type ProxyResource struct {
+	Name            string        `yaml:"name"`
+	Protocol        string        `yaml:"protocol"`
+	FullDomain      string        `yaml:"full-domain"`
+	SSL             bool          `yaml:"ssl,omitempty"`
+	Enabled         bool          `yaml:"enabled"`
+	ManagedBy       string        `yaml:"managed-by,omitempty"`
+	LoadBalancer    *LoadBalancer `yaml:"load-balancer,omitempty"`
+	HealthCheckPath string        `yaml:"health-check-path,omitempty"`
+	// HealthCheckInterval is a Go duration string (e.g. "10s") rather than
+	// time.Duration so the marshaled YAML stays human-readable.
+	HealthCheckInterval string `yaml:"health-check-interval,omitempty"`
+	// Headers, BasicAuth, RateLimit, and IPFilter are optional policy
+	// knobs applied by the middleware chain (see internal/middleware)
+	// before a blueprint is pushed; a resource with none of them carries
+	// no policy at all, which is the common case.
+	Headers   *Headers   `yaml:"headers,omitempty"`
+	BasicAuth *BasicAuth `yaml:"basic-auth,omitempty"`
+	RateLimit *RateLimit `yaml:"rate-limit,omitempty"`
+	IPFilter  *IPFilter  `yaml:"ip-filter,omitempty"`
+	Targets   []Target   `yaml:"targets"`
+}
+
+// Headers injects or strips request headers before they reach a target,
+// mirroring reproxy's ProxyHeaders/DropHeaders.
+type Headers struct {
+	Set  map[string]string `yaml:"set,omitempty"`
+	Drop []string          `yaml:"drop,omitempty"`
+}
+
+// BasicAuth gates a resource behind HTTP basic auth, checked by Pangolin
+// against an htpasswd file at request time.
+type BasicAuth struct {
+	HtpasswdFile string `yaml:"htpasswd-file"`
+}
+
+// RateLimit caps the request rate Pangolin allows through to a resource.
+type RateLimit struct {
+	RequestsPerSecond int `yaml:"requests-per-second"`
+	Burst             int `yaml:"burst,omitempty"`
+}
+
+// IPFilter allow/deny-lists client IPs and CIDRs in front of a resource.
+// Deny takes precedence over Allow.
+type IPFilter struct {
+	Allow []string `yaml:"allow,omitempty"`
+	Deny  []string `yaml:"deny,omitempty"`
+}
+
+// LBStrategy is the load-balancing algorithm Pangolin applies across a
+// resource's targets.
+type LBStrategy string
+
+const (
+	LBRandom     LBStrategy = "random"
+	LBRoundRobin LBStrategy = "roundrobin"
+	LBFailover   LBStrategy = "failover"
+	LBIPHash     LBStrategy = "iphash"
+)
+
+type LoadBalancer struct {
+	Strategy LBStrategy `yaml:"strategy"`
+}
+
+type Target struct {
+	Hostname string `yaml:"hostname"`
+	Port     int    `yaml:"port"`
+	Method   string `yaml:"method"`
+	Enabled  bool   `yaml:"enabled"`
+	Path     string `yaml:"path,omitempty"`
+	// Weight and Priority only matter when a resource has more than one
+	// target: weight spreads traffic under random/roundrobin, priority
+	// picks the active target under failover.
+	Weight   int `yaml:"weight,omitempty"`
+	Priority int `yaml:"priority,omitempty"`
+}
+
+// Resource is the summary Pangolin returns for an existing resource - just
+// enough for a reconcile pass to decide whether it owns it.
+//
+// ManagedBy round-trips the blueprint's "managed-by" field so prune (see
+// main.go's orphan-delete pass) can tell Dock Otter's own resources apart
+// from ones a human authored directly in Pangolin. Pangolin's REST API
+// isn't guaranteed to echo the same key case/style it accepts on write -
+// GET /v1/resources has been observed to answer with "managedBy" in some
+// deployments and "managed-by" in others - so UnmarshalJSON below accepts
+// either instead of silently reading an empty string and treating every
+// resource as unowned.
+type Resource struct {
+	Name      string `json:"name"`
+	ManagedBy string `json:"-"`
+}
+
+// UnmarshalJSON accepts either "managedBy" or "managed-by" for the
+// ManagedBy field - see the Resource doc comment for why both are handled.
+func (r *Resource) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Name           string `json:"name"`
+		ManagedByCamel string `json:"managedBy"`
+		ManagedByKebab string `json:"managed-by"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	r.Name = raw.Name
+	r.ManagedBy = raw.ManagedByCamel
+	if r.ManagedBy == "" {
+		r.ManagedBy = raw.ManagedByKebab
+	}
+	return nil
+}
+
+// Config holds the Pangolin connection settings.
+type Config struct {
+	URL    string
+	APIKey string
+	Token  string
+}
+
+// Client talks to the Pangolin API.
+type Client struct {
+	http *resty.Client
+	url  string
+}
+
+// New builds a Pangolin client configured the same way the rest of Dock
+// Otter talks to external APIs (timeouts, retries, auth).
+func New(cfg Config) *Client {
+	http := resty.New().
+		SetTimeout(30*time.Second).
+		SetRetryCount(2).
+		SetRetryWaitTime(1*time.Second).
+		SetRetryMaxWaitTime(5*time.Second).
+		SetHeader("User-Agent", "dock-otter/1.0").
+		SetHeader("Accept", "application/json")
+
+	if cfg.Token != "" {
+		http.SetHeader("Authorization", "Bearer "+cfg.Token)
+	} else if cfg.APIKey != "" {
+		http.SetHeader("Authorization", "Bearer "+cfg.APIKey)
+	}
+
+	metrics.Instrument(http, "pangolin")
+
+	return &Client{http: http, url: cfg.URL}
+}
+
+// Ping is a cheap connectivity check used at startup.
+func (c *Client) Ping(ctx context.Context) (int, error) {
+	resp, err := c.http.R().SetContext(ctx).Get(c.url + "/v1/docs")
+	if err != nil {
+		return 0, err
+	}
+	return resp.StatusCode(), nil
+}
+
+// ListResources returns every resource Pangolin currently knows about,
+// including ones Dock Otter doesn't own, so a reconcile pass can tell them
+// apart.
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	resp, err := c.http.R().
+		SetContext(ctx).
+		SetHeader("Accept", "application/json").
+		Get(c.url + "/v1/resources")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
+		return nil, errdefs.FromHTTPStatus(resp.StatusCode(), resp.String(), resp.Header())
+	}
+
+	var resources []Resource
+	if err := json.Unmarshal(resp.Body(), &resources); err != nil {
+		return nil, fmt.Errorf("failed to parse resource list: %w", err)
+	}
+	return resources, nil
+}
+
+// CreateBlueprint pushes a brand new blueprint.
+func (c *Client) CreateBlueprint(ctx context.Context, bp *Blueprint) error {
+	return c.postBlueprint(ctx, "POST", c.url+"/v1/blueprints", bp)
+}
+
+// UpdateBlueprint replaces the blueprint for an existing resource.
+func (c *Client) UpdateBlueprint(ctx context.Context, name string, bp *Blueprint) error {
+	return c.postBlueprint(ctx, "PUT", c.url+"/v1/blueprints/"+name, bp)
+}
+
+func (c *Client) postBlueprint(ctx context.Context, method, url string, bp *Blueprint) error {
+	yamlData, err := yaml.Marshal(bp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal blueprint: %w", err)
+	}
+
+	resp, err := c.http.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/yaml").
+		SetBody(yamlData).
+		Execute(method, url)
+	if err != nil {
+		return fmt.Errorf("failed to send blueprint: %w", err)
+	}
+
+	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
+		return errdefs.FromHTTPStatus(resp.StatusCode(), resp.String(), resp.Header())
+	}
+
+	return nil
+}
+
+// DeleteResource removes a resource Dock Otter previously created.
+func (c *Client) DeleteResource(ctx context.Context, name string) error {
+	resp, err := c.http.R().
+		SetContext(ctx).
+		Delete(c.url + "/v1/resources/" + name)
+	if err != nil {
+		return fmt.Errorf("failed to delete resource %s: %w", name, err)
+	}
+	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
+		return errdefs.FromHTTPStatus(resp.StatusCode(), resp.String(), resp.Header())
+	}
+	return nil
+}
+
+// Marshal renders a blueprint to YAML, used by callers that need to hash it
+// for change detection before deciding whether to push it.
+func Marshal(bp *Blueprint) ([]byte, error) {
+	return yaml.Marshal(bp)
+}
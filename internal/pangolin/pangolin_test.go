@@ -0,0 +1,34 @@
+package pangolin
+
+import "testing"
+
+// TestResourceUnmarshalJSON_ManagedBy verifies the field-name contract
+// documented on Resource: prune relies on ManagedBy surviving the round
+// trip through Pangolin's GET /v1/resources response regardless of
+// whether the deployment echoes "managedBy" or "managed-by".
+func TestResourceUnmarshalJSON_ManagedBy(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"camelCase key", `{"name":"svc","managedBy":"dock-otter"}`, "dock-otter"},
+		{"kebab-case key", `{"name":"svc","managed-by":"dock-otter"}`, "dock-otter"},
+		{"neither key present", `{"name":"svc"}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Resource
+			if err := r.UnmarshalJSON([]byte(tt.json)); err != nil {
+				t.Fatalf("UnmarshalJSON returned error: %v", err)
+			}
+			if r.Name != "svc" {
+				t.Errorf("Name = %q, want %q", r.Name, "svc")
+			}
+			if r.ManagedBy != tt.want {
+				t.Errorf("ManagedBy = %q, want %q", r.ManagedBy, tt.want)
+			}
+		})
+	}
+}
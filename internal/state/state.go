@@ -0,0 +1,99 @@
+// Package state persists the last-applied content hash for every resource
+// Dock Otter manages, so restarts don't re-push everything on the next
+// sync. It's a small JSON file rather than an embedded database - Dock
+// Otter's working set is a handful of resources, not something that needs
+// BoltDB-grade indexing.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// Record is what's stored per resource name.
+type Record struct {
+	Hash      string    `json:"hash"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Store is safe for concurrent use.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]Record
+}
+
+// Open loads the store from path, treating a missing file as an empty
+// store (the first run always has nothing to compare against).
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, records: make(map[string]Record)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.records); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Hash returns the last-applied hash for name, if any.
+func (s *Store) Hash(name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[name]
+	return r.Hash, ok
+}
+
+// Names returns every resource name currently tracked.
+func (s *Store) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.records))
+	for name := range s.records {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Set records the applied hash for name and persists the store to disk.
+func (s *Store) Set(name, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[name] = Record{Hash: hash, UpdatedAt: time.Now()}
+	data, err := json.MarshalIndent(s.records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0o644)
+}
+
+// Delete drops name from the store (used once its resource has been
+// removed from Pangolin) and persists the store to disk.
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, name)
+	data, err := json.MarshalIndent(s.records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0o644)
+}
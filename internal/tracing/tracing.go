@@ -0,0 +1,58 @@
+// Package tracing sets up Dock Otter's OpenTelemetry tracer provider: an
+// OTLP/gRPC exporter pointed at OTEL_EXPORTER_OTLP_ENDPOINT, or a no-op
+// provider if that's unset, so the sync loop's spans have somewhere to go
+// without requiring a collector for every deployment.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+const tracerName = "github.com/Gadgitmatic/Dock-Otter"
+
+// Tracer is the tracer every package should use to start spans. It's safe
+// to use before Init runs: it proxies to whatever provider is currently
+// registered globally, starting as a no-op until Init installs a real one.
+var Tracer = otel.Tracer(tracerName)
+
+// Init configures the global TracerProvider from OTEL_EXPORTER_OTLP_ENDPOINT.
+// If that's unset, tracing stays a no-op so Dock Otter doesn't require a
+// collector to start. The returned shutdown func flushes and closes the
+// exporter; callers should defer it.
+func Init(ctx context.Context, serviceName, version string) (shutdown func(context.Context) error, err error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OTel resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return tp.Shutdown, nil
+}
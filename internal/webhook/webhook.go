@@ -0,0 +1,77 @@
+// Package webhook implements the HMAC-verified Dokploy webhook endpoint
+// that lets Dock Otter react to a deploy immediately instead of waiting for
+// the next poll.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// SignatureHeader is the header Dokploy is expected to send the request
+// signature in, formatted as "sha256=<hex-hmac>".
+const SignatureHeader = "X-Dokploy-Signature"
+
+// Handler verifies the HMAC signature on incoming webhook requests and
+// calls Trigger for every valid one. It deliberately does nothing with the
+// request body beyond verifying it - a webhook firing is just a hint that
+// something changed, the next sync still reconciles full desired state.
+type Handler struct {
+	secret  []byte
+	Trigger func()
+}
+
+// New builds a Handler. secret is the shared value configured via
+// DOKPLOY_WEBHOOK_SECRET; trigger is called once per verified request.
+func New(secret string, trigger func()) *Handler {
+	return &Handler{secret: []byte(secret), Trigger: trigger}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if !h.verify(r.Header.Get(SignatureHeader), body) {
+		slog.Warn("⚠️  Rejected webhook with invalid signature")
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	slog.Info("🪝 Received verified Dokploy webhook, triggering sync")
+	h.Trigger()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) verify(header string, body []byte) bool {
+	if len(h.secret) == 0 {
+		return false
+	}
+
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+
+	expected, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write(body)
+	return hmac.Equal(expected, mac.Sum(nil))
+}
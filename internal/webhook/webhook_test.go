@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHandlerVerify(t *testing.T) {
+	const secret = "s3cr3t"
+	const body = `{"app":"demo"}`
+	h := New(secret, func() {})
+
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"valid signature", sign(secret, body), true},
+		{"wrong secret", sign("other-secret", body), false},
+		{"tampered body", sign(secret, `{"app":"evil"}`), false},
+		{"missing sha256 prefix", hex.EncodeToString([]byte("not-a-prefix")), false},
+		{"non-hex signature", "sha256=not-hex", false},
+		{"empty header", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.verify(tt.header, []byte(body)); got != tt.want {
+				t.Errorf("verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerVerify_NoSecretConfigured(t *testing.T) {
+	h := New("", func() {})
+	if h.verify(sign("anything", "body"), []byte("body")) {
+		t.Error("verify() = true with no secret configured, want false")
+	}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -13,8 +15,23 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/go-resty/resty/v2"
-	"gopkg.in/yaml.v3"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Gadgitmatic/Dock-Otter/internal/discovery"
+	"github.com/Gadgitmatic/Dock-Otter/internal/discovery/consulcatalog"
+	"github.com/Gadgitmatic/Dock-Otter/internal/discovery/dockerlabels"
+	"github.com/Gadgitmatic/Dock-Otter/internal/discovery/dokploy"
+	"github.com/Gadgitmatic/Dock-Otter/internal/discovery/staticfile"
+	"github.com/Gadgitmatic/Dock-Otter/internal/errdefs"
+	"github.com/Gadgitmatic/Dock-Otter/internal/metrics"
+	"github.com/Gadgitmatic/Dock-Otter/internal/middleware"
+	"github.com/Gadgitmatic/Dock-Otter/internal/pangolin"
+	"github.com/Gadgitmatic/Dock-Otter/internal/state"
+	"github.com/Gadgitmatic/Dock-Otter/internal/tracing"
+	"github.com/Gadgitmatic/Dock-Otter/internal/webhook"
 )
 
 // Build-time variables (set via ldflags)
@@ -26,78 +43,69 @@ var (
 
 // Configuration
 type Config struct {
-	DokployURL       string
-	DokployAPIKey    string
-	DokployToken     string
-	DokploySession   string
-	PangolinURL      string
-	PangolinAPIKey   string
-	PangolinToken    string
-	PollInterval     time.Duration
-	RetryAttempts    int
-	RetryDelay       time.Duration
-	RunOnce          bool // For manual execution
-	ForceSync        bool // Force re-sync existing resources
-}
-
-// Dokploy API types (based on actual API structure)
-type DokployProject struct {
-	ProjectID    string          `json:"projectId"`
-	Name         string          `json:"name"`
-	Description  string          `json:"description"`
-	Applications []DokployApp    `json:"applications"`
-	Compose      []DokployApp    `json:"compose"`
-}
-
-type DokployApp struct {
-	ApplicationID string `json:"applicationId,omitempty"`
-	ComposeID     string `json:"composeId,omitempty"`
-	Name          string `json:"name"`
-	AppName       string `json:"appName"`
-	Description   string `json:"description"`
-	Domains       []DokployDomain `json:"domains,omitempty"`
-	Port          int    `json:"port,omitempty"`
-	Status        string `json:"applicationStatus"`
-	ProjectID     string `json:"projectId"`
-}
-
-type DokployDomain struct {
-	DomainID    string `json:"domainId"`
-	Host        string `json:"host"`
-	Path        string `json:"path"`
-	Port        int    `json:"port"`
-	HTTPS       bool   `json:"https"`
-	Certificate string `json:"certificate,omitempty"`
-}
-
-// Pangolin Blueprint types (simplified for our use case)
-type PangolinBlueprint struct {
-	ProxyResources []ProxyResource `yaml:"proxy-resources"`
-}
-
-type ProxyResource struct {
-	Name       string   `yaml:"name"`
-	Protocol   string   `yaml:"protocol"`
-	FullDomain string   `yaml:"full-domain"`
-	SSL        bool     `yaml:"ssl,omitempty"`
-	Enabled    bool     `yaml:"enabled"`
-	Targets    []Target `yaml:"targets"`
-}
-
-type Target struct {
-	Hostname string `yaml:"hostname"`
-	Port     int    `yaml:"port"`
-	Method   string `yaml:"method"`
-	Enabled  bool   `yaml:"enabled"`
-	Path     string `yaml:"path,omitempty"`
+	DokployURL     string
+	DokployAPIKey  string
+	DokployToken   string
+	DokploySession string
+	PangolinURL    string
+	PangolinAPIKey string
+	PangolinToken  string
+	PollInterval   time.Duration
+	RetryAttempts  int
+	RetryDelay     time.Duration
+	RunOnce        bool // For manual execution
+	ForceSync      bool // Force re-sync existing resources
+
+	// DiscoveryProviders lists which providers are enabled, e.g.
+	// "dokploy,docker,file,consul". Unknown providers are skipped with a
+	// warning; a known provider that cannot be set up fails startup.
+	DiscoveryProviders []string
+	DockerLabelPrefix  string
+	StaticServicesFile string
+	ConsulAddr         string
+
+	// StatePath is where the last-applied hash of every managed resource is
+	// persisted, so restarts don't re-push everything.
+	StatePath string
+	// DryRun logs the create/update/delete plan without calling Pangolin.
+	DryRun bool
+
+	// WebhookSecret, if set, enables the /webhook/dokploy endpoint and is
+	// used to verify its HMAC signature.
+	WebhookSecret string
+	// DockerEventsDebounce coalesces a burst of Docker container events
+	// into a single sync.
+	DockerEventsDebounce time.Duration
+
+	// LBStrategy is applied to any resource with more than one backend.
+	LBStrategy pangolin.LBStrategy
+	// HealthCheckPath/HealthCheckInterval are the fleet-wide defaults used
+	// when a discovered service doesn't specify its own.
+	HealthCheckPath     string
+	HealthCheckInterval time.Duration
+
+	// MiddlewareConfigFile points at a YAML file's "middlewares:" section
+	// describing built-in policy (headers, basic auth, path rewrites,
+	// rate limits, IP filtering) to apply to every blueprint before it's
+	// pushed to Pangolin.
+	MiddlewareConfigFile string
+	// BasicHtpasswdFile, if set, wraps every resource in HTTP basic auth
+	// against this htpasswd file - a shorthand for sites that only need
+	// that one built-in and don't want to write a middlewares file.
+	BasicHtpasswdFile string
+	// PluginAddr, if set, routes every blueprint through an out-of-process
+	// plugin over net/rpc on this Unix socket, after the built-ins run.
+	PluginAddr string
 }
 
 // Main application
 type DockOtter struct {
-	config        *Config
-	dokployClient *resty.Client
-	pangolinClient *resty.Client
-	processedApps map[string]bool
+	config         *Config
+	pangolinClient *pangolin.Client
+	providers      []discovery.Provider
+	state          *state.Store
+	middlewares    *middleware.Chain
+	syncTrigger    chan struct{}
 }
 
 func main() {
@@ -128,17 +136,35 @@ func main() {
 	slog.Info("🦦 Dock Otter starting up...")
 
 	cfg := loadConfig()
+	if hasFlag("--dry-run") {
+		cfg.DryRun = true
+	}
 	if err := validateConfig(cfg); err != nil {
 		slog.Error("❌ Configuration error", "error", err)
 		os.Exit(1)
 	}
-	
-	app := NewDockOtter(cfg)
+
+	shutdownTracing, err := tracing.Init(context.Background(), "dock-otter", version)
+	if err != nil {
+		slog.Error("❌ Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Warn("⚠️  Failed to flush traces on shutdown", "error", err)
+		}
+	}()
+
+	app, err := NewDockOtter(cfg)
+	if err != nil {
+		slog.Error("❌ Failed to initialize", "error", err)
+		os.Exit(1)
+	}
 
 	// Check for manual execution mode
 	if cfg.RunOnce {
 		slog.Info("🔄 Running in manual mode (single execution)")
-		if err := app.syncApps(); err != nil {
+		if err := app.syncApps(context.Background()); err != nil {
 			slog.Error("❌ Manual sync failed", "error", err)
 			os.Exit(1)
 		}
@@ -146,8 +172,9 @@ func main() {
 		return
 	}
 
-	// Start health check server for daemon mode
-	go startHealthServer()
+	// Start health check server for daemon mode, with the Dokploy webhook
+	// endpoint wired to trigger an immediate sync.
+	go startHealthServer(app)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -171,70 +198,204 @@ func main() {
 	slog.Info("🦦 Dock Otter stopped")
 }
 
-func NewDockOtter(cfg *Config) *DockOtter {
-	// Setup Dokploy client with auth and 2025 best practices
-	dokployClient := resty.New().
-		SetTimeout(30 * time.Second).
-		SetRetryCount(2).
-		SetRetryWaitTime(1 * time.Second).
-		SetRetryMaxWaitTime(5 * time.Second).
-		SetHeader("User-Agent", "dock-otter/1.0").
-		SetHeader("Accept", "application/json")
-
-	if cfg.DokployAPIKey != "" {
-		dokployClient.SetHeader("X-API-Key", cfg.DokployAPIKey)
-	}
-	if cfg.DokployToken != "" {
-		dokployClient.SetHeader("Authorization", "Bearer "+cfg.DokployToken)
+func hasFlag(flag string) bool {
+	for _, arg := range os.Args[1:] {
+		if arg == flag {
+			return true
+		}
 	}
-	if cfg.DokploySession != "" {
-		dokployClient.SetHeader("Cookie", "session="+cfg.DokploySession)
+	return false
+}
+
+// NewDockOtter builds the enabled discovery providers, the Pangolin client,
+// and loads the persisted reconcile state.
+func NewDockOtter(cfg *Config) (*DockOtter, error) {
+	pangolinClient := pangolin.New(pangolin.Config{
+		URL:    cfg.PangolinURL,
+		APIKey: cfg.PangolinAPIKey,
+		Token:  cfg.PangolinToken,
+	})
+
+	var providers []discovery.Provider
+	for _, name := range cfg.DiscoveryProviders {
+		switch name {
+		case "dokploy":
+			providers = append(providers, dokploy.New(dokploy.Config{
+				URL:     cfg.DokployURL,
+				APIKey:  cfg.DokployAPIKey,
+				Token:   cfg.DokployToken,
+				Session: cfg.DokploySession,
+			}))
+		case "docker":
+			p, err := dockerlabels.New(cfg.DockerLabelPrefix)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set up docker provider: %w", err)
+			}
+			providers = append(providers, p)
+		case "file":
+			if cfg.StaticServicesFile == "" {
+				return nil, fmt.Errorf("file provider enabled but STATIC_SERVICES_FILE is not set")
+			}
+			providers = append(providers, staticfile.New(cfg.StaticServicesFile))
+		case "consul":
+			p, err := consulcatalog.New(cfg.ConsulAddr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set up consul provider: %w", err)
+			}
+			providers = append(providers, p)
+		default:
+			slog.Warn("⚠️  Unknown discovery provider, ignoring", "provider", name)
+		}
 	}
 
-	// Setup Pangolin client with Bearer token auth and best practices
-	pangolinClient := resty.New().
-		SetTimeout(30 * time.Second).
-		SetRetryCount(2).
-		SetRetryWaitTime(1 * time.Second).
-		SetRetryMaxWaitTime(5 * time.Second).
-		SetHeader("User-Agent", "dock-otter/1.0").
-		SetHeader("Accept", "application/json")
+	stateStore, err := state.Open(cfg.StatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state store at %s: %w", cfg.StatePath, err)
+	}
 
-	if cfg.PangolinToken != "" {
-		pangolinClient.SetHeader("Authorization", "Bearer "+cfg.PangolinToken)
-	} else if cfg.PangolinAPIKey != "" {
-		pangolinClient.SetHeader("Authorization", "Bearer "+cfg.PangolinAPIKey)
+	mwChain, err := buildMiddlewareChain(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build middleware chain: %w", err)
 	}
 
 	return &DockOtter{
 		config:         cfg,
-		dokployClient:  dokployClient,
 		pangolinClient: pangolinClient,
-		processedApps:  make(map[string]bool),
+		providers:      providers,
+		state:          stateStore,
+		middlewares:    mwChain,
+		syncTrigger:    make(chan struct{}, 1),
+	}, nil
+}
+
+// buildMiddlewareChain loads the built-in middlewares from
+// MiddlewareConfigFile, if set, and fills in BasicHtpasswdFile and
+// PluginAddr where the file leaves those sections out, so Build's fixed
+// order applies and the out-of-process plugin runs last and sees every
+// built-in's output.
+func buildMiddlewareChain(cfg *Config) (*middleware.Chain, error) {
+	mwConfig := &middleware.Config{}
+	if cfg.MiddlewareConfigFile != "" {
+		loaded, err := middleware.LoadFile(cfg.MiddlewareConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		mwConfig = loaded
+	}
+
+	if cfg.BasicHtpasswdFile != "" && mwConfig.BasicAuth == nil {
+		mwConfig.BasicAuth = &middleware.BasicAuthConfig{HtpasswdFile: cfg.BasicHtpasswdFile}
+	}
+
+	if cfg.PluginAddr != "" && mwConfig.Plugin == nil {
+		mwConfig.Plugin = &middleware.PluginConfig{Addr: cfg.PluginAddr}
 	}
+
+	return mwConfig.Build()
 }
 
-func (d *DockOtter) Run(ctx context.Context) error {
-	slog.Info("🦦 Starting adapter", "poll_interval", d.config.PollInterval)
+// webhookHandler builds the Dokploy webhook handler when a secret is
+// configured, or nil otherwise so the endpoint is simply unregistered.
+func (d *DockOtter) webhookHandler() *webhook.Handler {
+	if d.config.WebhookSecret == "" {
+		return nil
+	}
+	return webhook.New(d.config.WebhookSecret, d.enqueueSync)
+}
 
-	// Log authentication status with structured logging
-	dokployAuth := d.getDokployAuthType()
-	pangolinAuth := d.getPangolinAuthType()
+// enqueueSync requests a sync without blocking; if one is already queued,
+// this is a no-op, which is exactly the coalescing behavior a burst of
+// triggers needs.
+func (d *DockOtter) enqueueSync() {
+	select {
+	case d.syncTrigger <- struct{}{}:
+	default:
+	}
+}
 
-	slog.Info("🔐 Authentication configured", 
-		"dokploy_auth", dokployAuth, 
-		"pangolin_auth", pangolinAuth)
+// callWithRetry runs fn, retrying transient failures (rate limits,
+// unavailability) with exponential backoff - honoring a server-provided
+// Retry-After when present - while giving up immediately on failures a
+// retry could never fix (invalid input, bad auth). Every failure, retried
+// or not, is tallied by kind for the /metrics endpoint.
+func (d *DockOtter) callWithRetry(ctx context.Context, op string, fn func() error) error {
+	delay := d.config.RetryDelay
+
+	var err error
+	for attempt := 1; attempt <= d.config.RetryAttempts; attempt++ {
+		err = fn()
+		if err == nil {
+			return nil
+		}
+		metrics.SyncErrorsTotal.WithLabelValues(errdefs.KindOf(err).String()).Inc()
+
+		if !errdefs.IsRateLimited(err) && !errdefs.IsUnavailable(err) {
+			return err
+		}
+		if attempt == d.config.RetryAttempts {
+			break
+		}
+
+		wait := delay
+		if retryAfter, ok := errdefs.RetryAfter(err); ok {
+			wait = retryAfter
+		}
+		slog.Warn("⏳ Retrying after transient error", "op", op, "attempt", attempt, "wait", wait, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+	return err
+}
+
+func (d *DockOtter) dockerProvider() (*dockerlabels.Provider, bool) {
+	for _, p := range d.providers {
+		if dp, ok := p.(*dockerlabels.Provider); ok {
+			return dp, true
+		}
+	}
+	return nil, false
+}
+
+func (d *DockOtter) Run(ctx context.Context) error {
+	slog.Info("🦦 Starting adapter", "poll_interval", d.config.PollInterval, "dry_run", d.config.DryRun)
+	slog.Info("🔐 Discovery providers configured", "providers", providerNames(d.providers))
 
 	// Test connectivity
-	if err := d.testConnectivity(); err != nil {
+	if err := d.testConnectivity(ctx); err != nil {
 		slog.Warn("⚠️  Connectivity test failed", "error", err)
 	}
 
-	ticker := time.NewTicker(d.config.PollInterval)
-	defer ticker.Stop()
+	// The periodic ticker is a safety net - it catches anything a webhook
+	// or Docker event missed - so it can be long relative to how fast
+	// DISCOVERY_PROVIDERS actually change.
+	go func() {
+		ticker := time.NewTicker(d.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.enqueueSync()
+			}
+		}
+	}()
+
+	if dp, ok := d.dockerProvider(); ok {
+		go dp.Watch(ctx, d.config.DockerEventsDebounce, d.enqueueSync)
+	}
+
+	if d.config.WebhookSecret != "" {
+		slog.Info("🪝 Dokploy webhook endpoint enabled at /webhook/dokploy")
+	}
 
 	// Initial sync
-	if err := d.syncApps(); err != nil {
+	if err := d.syncApps(ctx); err != nil {
 		slog.Error("❌ Initial sync failed", "error", err)
 	}
 
@@ -242,331 +403,326 @@ func (d *DockOtter) Run(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-ticker.C:
-			if err := d.syncApps(); err != nil {
+		case <-d.syncTrigger:
+			if err := d.syncApps(ctx); err != nil {
 				slog.Error("❌ Sync failed", "error", err)
 			}
 		}
 	}
 }
 
-func (d *DockOtter) getDokployAuthType() string {
-	if d.config.DokployAPIKey != "" {
-		return "API key"
-	} else if d.config.DokployToken != "" {
-		return "Bearer token"
-	} else if d.config.DokploySession != "" {
-		return "Session cookie"
+func providerNames(providers []discovery.Provider) []string {
+	names := make([]string, 0, len(providers))
+	for _, p := range providers {
+		names = append(names, p.Name())
 	}
-	return "none"
+	return names
 }
 
-func (d *DockOtter) getPangolinAuthType() string {
-	if d.config.PangolinToken != "" {
-		return "Bearer token"
-	} else if d.config.PangolinAPIKey != "" {
-		return "Bearer token (from API key)"
-	}
-	return "none"
-}
-
-func (d *DockOtter) testConnectivity() error {
-	slog.Info("🔍 Testing API connectivity...")
+func (d *DockOtter) testConnectivity(ctx context.Context) error {
+	slog.Info("🔍 Testing discovery provider connectivity...")
 
-	// Test Dokploy - get projects
-	projects, err := d.getDokployProjects()
-	if err != nil {
-		slog.Error("❌ Dokploy connection failed", "error", err)
-		return err
-	}
-	
-	totalApps := 0
-	totalDomains := 0
-	for _, project := range projects {
-		for _, app := range project.Applications {
-			totalApps++
-			totalDomains += len(app.Domains)
-		}
-		for _, app := range project.Compose {
-			totalApps++
-			totalDomains += len(app.Domains)
+	var lastErr error
+	for _, p := range d.providers {
+		services, err := p.List(ctx)
+		if err != nil {
+			slog.Error("❌ Provider connection failed", "provider", p.Name(), "error", err)
+			lastErr = err
+			continue
 		}
+		slog.Info("✅ Provider connected", "provider", p.Name(), "services", len(services))
 	}
-	slog.Info("✅ Dokploy connected", 
-		"projects", len(projects), 
-		"apps", totalApps, 
-		"domains", totalDomains)
 
-	// Test Pangolin - simple connectivity check
-	resp, err := d.pangolinClient.R().Get(d.config.PangolinURL + "/v1/docs")
+	status, err := d.pangolinClient.Ping(ctx)
 	if err != nil {
 		slog.Warn("⚠️  Pangolin connectivity test failed", "error", err)
 	} else {
-		slog.Info("✅ Pangolin API accessible", "status", resp.StatusCode())
+		slog.Info("✅ Pangolin API accessible", "status", status)
 	}
-	
-	return nil
+
+	return lastErr
 }
 
-func (d *DockOtter) syncApps() error {
-	slog.Info("🔄 Syncing apps from Dokploy...")
+// syncApps reconciles Pangolin against the merged desired state from every
+// enabled provider: it creates resources that are missing, updates ones
+// whose content changed, and deletes resources Dock Otter previously
+// created that no longer have a matching service.
+func (d *DockOtter) syncApps(ctx context.Context) error {
+	ctx, span := tracing.Tracer.Start(ctx, "syncApps")
+	defer span.End()
 
-	projects, err := d.getDokployProjects()
+	start := time.Now()
+	defer func() { metrics.SyncDuration.Observe(time.Since(start).Seconds()) }()
+
+	slog.Info("🔄 Reconciling Pangolin resources against discovered services...")
+
+	desired, order, err := d.buildDesiredResources(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get projects: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to build desired state: %w", err)
 	}
 
-	processed := 0
-	skipped := 0
-	errors := 0
+	actual, err := d.pangolinClient.ListResources(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to list pangolin resources: %w", err)
+	}
+	actualByName := make(map[string]pangolin.Resource, len(actual))
+	for _, r := range actual {
+		actualByName[r.Name] = r
+	}
 
-	for _, project := range projects {
-		slog.Debug("Processing project", "project", project.Name, "id", project.ProjectID)
-		
-		// Process regular applications
-		for _, app := range project.Applications {
-			if app.Status != "done" {
-				slog.Debug("Skipping app - not running", "app", app.Name, "status", app.Status)
-				skipped++
-				continue
-			}
+	created, updated, deleted, skipped, errs := 0, 0, 0, 0, 0
 
-			if len(app.Domains) == 0 {
-				slog.Debug("Skipping app - no domains", "app", app.Name)
-				skipped++
-				continue
-			}
+	for _, name := range order {
+		bp := &pangolin.Blueprint{ProxyResources: []pangolin.ProxyResource{*desired[name]}}
 
-			for _, domain := range app.Domains {
-				if err := d.processAppDomain(app, domain); err != nil {
-					slog.Error("❌ Failed to process app domain", 
-						"app", app.Name, 
-						"domain", domain.Host, 
-						"error", err)
-					errors++
-				} else {
-					processed++
-				}
-			}
+		bp, err := d.middlewares.Apply(ctx, bp)
+		if err != nil {
+			slog.Error("❌ Middleware chain rejected blueprint", "resource", name, "error", err)
+			errs++
+			continue
 		}
 
-		// Process compose applications
-		for _, app := range project.Compose {
-			if app.Status != "done" {
-				slog.Debug("Skipping compose - not running", "compose", app.Name, "status", app.Status)
-				skipped++
-				continue
-			}
+		data, err := pangolin.Marshal(bp)
+		if err != nil {
+			slog.Error("❌ Failed to marshal blueprint", "resource", name, "error", err)
+			errs++
+			continue
+		}
+		hash := contentHash(data)
 
-			if len(app.Domains) == 0 {
-				slog.Debug("Skipping compose - no domains", "compose", app.Name)
-				skipped++
-				continue
-			}
+		_, existsRemote := actualByName[name]
+		lastHash, known := d.state.Hash(name)
+
+		switch decideReconcileAction(existsRemote, known, lastHash, hash, d.config.ForceSync) {
+		case actionSkip:
+			skipped++
+			metrics.ResourcesSkippedTotal.Inc()
+			continue
 
-			for _, domain := range app.Domains {
-				if err := d.processAppDomain(app, domain); err != nil {
-					slog.Error("❌ Failed to process compose domain", 
-						"compose", app.Name, 
-						"domain", domain.Host, 
-						"error", err)
-					errors++
-				} else {
-					processed++
+		case actionCreate:
+			slog.Info("🔧 Creating Pangolin resource", "resource", name, "dry_run", d.config.DryRun)
+			if !d.config.DryRun {
+				if err := d.callWithRetry(ctx, "create", func() error { return d.pangolinClient.CreateBlueprint(ctx, bp) }); err != nil {
+					slog.Error("❌ Failed to create resource", "resource", name, "error", err)
+					errs++
+					continue
+				}
+				if err := d.state.Set(name, hash); err != nil {
+					slog.Error("❌ Failed to persist state", "resource", name, "error", err)
+				}
+			}
+			created++
+			metrics.ResourcesCreatedTotal.Inc()
+
+		case actionUpdate:
+			slog.Info("🔧 Updating Pangolin resource", "resource", name, "dry_run", d.config.DryRun)
+			if !d.config.DryRun {
+				if err := d.callWithRetry(ctx, "update", func() error { return d.pangolinClient.UpdateBlueprint(ctx, name, bp) }); err != nil {
+					slog.Error("❌ Failed to update resource", "resource", name, "error", err)
+					errs++
+					continue
+				}
+				if err := d.state.Set(name, hash); err != nil {
+					slog.Error("❌ Failed to persist state", "resource", name, "error", err)
 				}
 			}
+			updated++
+			metrics.ResourcesUpdatedTotal.Inc()
 		}
 	}
 
-	slog.Info("✅ Sync completed", 
-		"processed", processed, 
-		"skipped", skipped, 
-		"errors", errors)
-	return nil
-}
-
-func (d *DockOtter) processAppDomain(app DokployApp, domain DokployDomain) error {
-	resourceName := d.generateResourceName(app.Name, domain.Host)
-
-	// Check if already processed (unless force sync is enabled)
-	if !d.config.ForceSync && d.processedApps[resourceName] {
-		return nil
-	}
-
-	// Validate required fields
-	if domain.Host == "" {
-		return fmt.Errorf("domain host is empty")
-	}
+	for name, remote := range actualByName {
+		if _, stillDesired := desired[name]; stillDesired {
+			continue
+		}
+		if remote.ManagedBy != pangolin.ManagedByDockOtter {
+			// Not ours - a human authored this directly in Pangolin, leave it alone.
+			continue
+		}
 
-	// Enhanced port resolution logic for 2025 compatibility
-	targetPort := d.resolveTargetPort(app, domain)
-	if targetPort == 0 {
-		return fmt.Errorf("no port available for app %s domain %s", app.Name, domain.Host)
+		slog.Info("🗑️  Deleting orphaned Pangolin resource", "resource", name, "dry_run", d.config.DryRun)
+		if !d.config.DryRun {
+			if err := d.callWithRetry(ctx, "delete", func() error { return d.pangolinClient.DeleteResource(ctx, name) }); err != nil {
+				slog.Error("❌ Failed to delete resource", "resource", name, "error", err)
+				errs++
+				continue
+			}
+			if err := d.state.Delete(name); err != nil {
+				slog.Error("❌ Failed to clear state", "resource", name, "error", err)
+			}
+		}
+		deleted++
+		metrics.ResourcesDeletedTotal.Inc()
 	}
 
-	// Determine target method and hostname with better logic
-	targetMethod := "http"
-	targetHostname := app.AppName
-	
-	if domain.HTTPS {
-		targetMethod = "https"
+	slog.Info("✅ Reconcile completed",
+		"created", created,
+		"updated", updated,
+		"deleted", deleted,
+		"skipped", skipped,
+		"errors", errs)
+	if errs == 0 {
+		metrics.SyncLastSuccessTimestamp.Set(float64(time.Now().Unix()))
 	}
-
-	// Handle path-based routing if specified
-	targetPath := "/"
-	if domain.Path != "" && domain.Path != "/" {
-		targetPath = domain.Path
-	}
-
-	slog.Info("🔧 Creating Pangolin resource", 
-		"domain", domain.Host,
-		"app", app.Name,
-		"hostname", targetHostname,
-		"port", targetPort,
-		"method", targetMethod,
-		"path", targetPath,
-		"ssl", domain.HTTPS)
-
-	// Create enhanced Pangolin blueprint with better domain/port mapping
-	blueprint := &PangolinBlueprint{
-		ProxyResources: []ProxyResource{
-			{
-				Name:       resourceName,
-				Protocol:   "http",
-				FullDomain: domain.Host,
-				SSL:        domain.HTTPS,
-				Enabled:    true,
-				Targets: []Target{
-					{
-						Hostname: targetHostname,
-						Port:     targetPort,
-						Method:   targetMethod,
-						Enabled:  true,
-						Path:     targetPath,
-					},
-				},
-			},
-		},
-	}
-
-	if err := d.createBlueprintWithRetry(blueprint); err != nil {
-		return fmt.Errorf("failed to create blueprint: %w", err)
-	}
-
-	d.processedApps[resourceName] = true
-	slog.Info("✅ Pangolin resource created", "resource", resourceName, "domain", domain.Host)
 	return nil
 }
 
-// Enhanced port resolution with fallback logic
-func (d *DockOtter) resolveTargetPort(app DokployApp, domain DokployDomain) int {
-	// Priority 1: Domain-specific port
-	if domain.Port > 0 {
-		return domain.Port
-	}
+// buildDesiredResources lists every enabled provider, merges the results
+// (deduplicated by service name, first provider to claim a name wins), and
+// converts each service/domain pair into the Pangolin resource that should
+// exist for it. order preserves a stable iteration order for logging.
+func (d *DockOtter) buildDesiredResources(ctx context.Context) (map[string]*pangolin.ProxyResource, []string, error) {
+	merged := make(map[string]discovery.Service)
+	var mergedOrder []string
 
-	// Priority 2: Application port
-	if app.Port > 0 {
-		return app.Port
-	}
+	for _, p := range d.providers {
+		services, err := p.List(ctx)
+		if err != nil {
+			slog.Error("❌ Failed to list services", "provider", p.Name(), "error", err)
+			continue
+		}
+		metrics.ResourcesManaged.WithLabelValues(p.Name()).Set(float64(len(services)))
 
-	// Priority 3: Default ports based on protocol
-	if domain.HTTPS {
-		return 443
+		for _, svc := range services {
+			if _, exists := merged[svc.Name]; exists {
+				slog.Debug("Skipping duplicate service name", "name", svc.Name, "provider", p.Name())
+				continue
+			}
+			merged[svc.Name] = svc
+			mergedOrder = append(mergedOrder, svc.Name)
+		}
 	}
-	return 80
-}
 
-func (d *DockOtter) getDokployProjects() ([]DokployProject, error) {
-	// Try multiple endpoints for different Dokploy versions
-	endpoints := []string{
-		"/api/projects",
-		"/api/project/all", 
-		"/api/project",
-		"/api/applications",
-	}
-	
-	var lastErr error
-	for _, endpoint := range endpoints {
-		resp, err := d.dokployClient.R().
-			SetHeader("Accept", "application/json").
-			Get(d.config.DokployURL + endpoint)
-		
-		if err != nil {
-			lastErr = err
+	desired := make(map[string]*pangolin.ProxyResource)
+	var order []string
+
+	for _, name := range mergedOrder {
+		svc := merged[name]
+
+		if len(svc.Domains) == 0 || len(svc.Backends) == 0 {
+			slog.Debug("Skipping service - missing domain or backend", "service", svc.Name)
 			continue
 		}
-		
-		if resp.StatusCode() == 200 {
-			var projects []DokployProject
-			if err := json.Unmarshal(resp.Body(), &projects); err != nil {
-				lastErr = err
+
+		for _, domain := range svc.Domains {
+			if domain == "" {
 				continue
 			}
-			slog.Info("✅ Found working Dokploy endpoint", "endpoint", endpoint)
-			return projects, nil
+			resourceName, resource := d.processAppDomain(ctx, svc, domain)
+			desired[resourceName] = resource
+			order = append(order, resourceName)
 		}
-		
-		lastErr = fmt.Errorf("endpoint %s returned status %d", endpoint, resp.StatusCode())
 	}
 
-	return nil, fmt.Errorf("all endpoints failed, last error: %w", lastErr)
+	return desired, order, nil
 }
 
-func (d *DockOtter) createBlueprintWithRetry(blueprint *PangolinBlueprint) error {
-	var lastErr error
+// processAppDomain builds the Pangolin resource for one domain of one
+// discovered service, wrapped in its own span so a slow or failing domain
+// shows up in a trace without having to dig through the whole reconcile
+// pass.
+func (d *DockOtter) processAppDomain(ctx context.Context, svc discovery.Service, domain string) (string, *pangolin.ProxyResource) {
+	_, span := tracing.Tracer.Start(ctx, "processAppDomain", trace.WithAttributes(
+		attribute.String("service.name", svc.Name),
+		attribute.String("domain", domain),
+	))
+	defer span.End()
+
+	resourceName := generateResourceName(svc.Name, domain)
+	return resourceName, d.buildResource(resourceName, svc, domain)
+}
 
-	for attempt := 1; attempt <= d.config.RetryAttempts; attempt++ {
-		err := d.createBlueprint(blueprint)
-		if err == nil {
-			return nil
-		}
+func (d *DockOtter) buildResource(resourceName string, svc discovery.Service, domain string) *pangolin.ProxyResource {
+	targetMethod := "http"
+	if svc.TLS {
+		targetMethod = "https"
+	}
 
-		lastErr = err
-		if attempt < d.config.RetryAttempts {
-			slog.Warn("⚠️  Blueprint creation failed, retrying", 
-				"attempt", attempt, 
-				"retry_in", d.config.RetryDelay, 
-				"error", err)
-			time.Sleep(d.config.RetryDelay)
+	targetPath := "/"
+	if path := svc.Labels["path"]; path != "" && path != "/" {
+		targetPath = path
+	}
+
+	targets := make([]pangolin.Target, 0, len(svc.Backends))
+	for _, b := range svc.Backends {
+		targets = append(targets, pangolin.Target{
+			Hostname: b.Hostname,
+			Port:     b.Port,
+			Method:   targetMethod,
+			Enabled:  true,
+			Path:     targetPath,
+			Weight:   b.Weight,
+			Priority: b.Priority,
+		})
+	}
+
+	resource := &pangolin.ProxyResource{
+		Name:       resourceName,
+		Protocol:   "http",
+		FullDomain: domain,
+		SSL:        svc.TLS,
+		Enabled:    true,
+		ManagedBy:  pangolin.ManagedByDockOtter,
+		Targets:    targets,
+	}
+
+	if len(targets) > 1 {
+		resource.LoadBalancer = &pangolin.LoadBalancer{Strategy: d.config.LBStrategy}
+	}
+
+	healthCheckPath := svc.HealthCheckPath
+	if healthCheckPath == "" {
+		healthCheckPath = d.config.HealthCheckPath
+	}
+	if healthCheckPath != "" {
+		resource.HealthCheckPath = healthCheckPath
+		interval := svc.HealthCheckInterval
+		if interval == 0 {
+			interval = d.config.HealthCheckInterval
 		}
+		resource.HealthCheckInterval = interval.String()
 	}
 
-	return fmt.Errorf("all %d attempts failed, last error: %w", d.config.RetryAttempts, lastErr)
+	return resource
 }
 
-func (d *DockOtter) createBlueprint(blueprint *PangolinBlueprint) error {
-	yamlData, err := yaml.Marshal(blueprint)
-	if err != nil {
-		return fmt.Errorf("failed to marshal blueprint: %w", err)
-	}
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
 
-	// Log the YAML for debugging in debug mode
-	slog.Debug("📄 Blueprint YAML", "yaml", string(yamlData))
+// reconcileAction is the outcome of comparing a desired resource against
+// what's already deployed - what syncApps's per-resource loop does about it.
+type reconcileAction int
 
-	// Use the correct Pangolin API endpoint with enhanced error handling
-	resp, err := d.pangolinClient.R().
-		SetHeader("Content-Type", "application/yaml").
-		SetBody(yamlData).
-		Post(d.config.PangolinURL + "/v1/blueprints")
+const (
+	actionSkip reconcileAction = iota
+	actionCreate
+	actionUpdate
+)
 
-	if err != nil {
-		return fmt.Errorf("failed to create blueprint: %w", err)
+// decideReconcileAction implements the create/update/skip decision matrix: a
+// resource already deployed with a matching content hash is left alone
+// unless force is set, a resource Pangolin doesn't know about yet is
+// created, and everything else (a known resource whose hash changed, or
+// force) is updated in place.
+func decideReconcileAction(existsRemote, knownHash bool, lastHash, hash string, force bool) reconcileAction {
+	if !force && existsRemote && knownHash && lastHash == hash {
+		return actionSkip
 	}
-
-	if resp.StatusCode() < 200 || resp.StatusCode() >= 300 {
-		slog.Error("Pangolin API error", 
-			"status", resp.StatusCode(), 
-			"response", resp.String())
-		return fmt.Errorf("pangolin API returned status %d: %s", resp.StatusCode(), resp.String())
+	if !existsRemote {
+		return actionCreate
 	}
-
-	return nil
+	return actionUpdate
 }
 
-func (d *DockOtter) generateResourceName(appName, domain string) string {
+func generateResourceName(serviceName, domain string) string {
 	// Create a safe name for Kubernetes/Pangolin
-	name := fmt.Sprintf("%s-%s", appName, domain)
+	name := fmt.Sprintf("%s-%s", serviceName, domain)
 	name = strings.ToLower(name)
 	name = strings.ReplaceAll(name, ".", "-")
 	name = strings.ReplaceAll(name, "_", "-")
@@ -581,34 +737,53 @@ func (d *DockOtter) generateResourceName(appName, domain string) string {
 
 func loadConfig() *Config {
 	return &Config{
-		DokployURL:       getEnv("DOKPLOY_URL", "http://dokploy:3000"),
-		DokployAPIKey:    getEnv("DOKPLOY_API_KEY", ""),
-		DokployToken:     getEnv("DOKPLOY_TOKEN", ""),
-		DokploySession:   getEnv("DOKPLOY_SESSION", ""),
-		PangolinURL:      getEnv("PANGOLIN_URL", "http://pangolin:3001"),
-		PangolinAPIKey:   getEnv("PANGOLIN_API_KEY", ""),
-		PangolinToken:    getEnv("PANGOLIN_TOKEN", ""),
-		PollInterval:     getDurationEnv("POLL_INTERVAL", 30*time.Second),
-		RetryAttempts:    getIntEnv("RETRY_ATTEMPTS", 3),
-		RetryDelay:       getDurationEnv("RETRY_DELAY", 5*time.Second),
-		RunOnce:          getBoolEnv("RUN_ONCE", false),
-		ForceSync:        getBoolEnv("FORCE_SYNC", false),
+		DokployURL:     getEnv("DOKPLOY_URL", "http://dokploy:3000"),
+		DokployAPIKey:  getEnv("DOKPLOY_API_KEY", ""),
+		DokployToken:   getEnv("DOKPLOY_TOKEN", ""),
+		DokploySession: getEnv("DOKPLOY_SESSION", ""),
+		PangolinURL:    getEnv("PANGOLIN_URL", "http://pangolin:3001"),
+		PangolinAPIKey: getEnv("PANGOLIN_API_KEY", ""),
+		PangolinToken:  getEnv("PANGOLIN_TOKEN", ""),
+		PollInterval:   getDurationEnv("POLL_INTERVAL", 30*time.Second),
+		RetryAttempts:  getIntEnv("RETRY_ATTEMPTS", 3),
+		RetryDelay:     getDurationEnv("RETRY_DELAY", 5*time.Second),
+		RunOnce:        getBoolEnv("RUN_ONCE", false),
+		ForceSync:      getBoolEnv("FORCE_SYNC", false),
+
+		DiscoveryProviders: getListEnv("DISCOVERY_PROVIDERS", []string{"dokploy"}),
+		DockerLabelPrefix:  getEnv("DOCKER_LABEL_PREFIX", "dockotter"),
+		StaticServicesFile: getEnv("STATIC_SERVICES_FILE", ""),
+		ConsulAddr:         getEnv("CONSUL_ADDR", ""),
+
+		StatePath: getEnv("STATE_PATH", "./dock-otter-state.json"),
+		DryRun:    getBoolEnv("DRY_RUN", false),
+
+		WebhookSecret:        getEnv("DOKPLOY_WEBHOOK_SECRET", ""),
+		DockerEventsDebounce: getDurationEnv("DOCKER_EVENTS_DEBOUNCE", 2*time.Second),
+
+		LBStrategy:          pangolin.LBStrategy(getEnv("LB_STRATEGY", string(pangolin.LBRandom))),
+		HealthCheckPath:     getEnv("HEALTH_CHECK_PATH", ""),
+		HealthCheckInterval: getDurationEnv("HEALTH_CHECK_INTERVAL", 10*time.Second),
+
+		MiddlewareConfigFile: getEnv("MIDDLEWARE_CONFIG", ""),
+		BasicHtpasswdFile:    getEnv("BASIC_HTPASSWD", ""),
+		PluginAddr:           getEnv("PLUGIN_ADDR", ""),
 	}
 }
 
 func validateConfig(cfg *Config) error {
-	// Check required URLs
-	if cfg.DokployURL == "" {
-		return fmt.Errorf("DOKPLOY_URL is required")
+	if len(cfg.DiscoveryProviders) == 0 {
+		return fmt.Errorf("at least one discovery provider must be enabled via DISCOVERY_PROVIDERS")
 	}
-	if cfg.PangolinURL == "" {
-		return fmt.Errorf("PANGOLIN_URL is required")
+
+	for _, p := range cfg.DiscoveryProviders {
+		if p == "dokploy" && cfg.DokployURL == "" {
+			return fmt.Errorf("DOKPLOY_URL is required when the dokploy provider is enabled")
+		}
 	}
 
-	// Check authentication - at least one method for each service
-	dokployAuth := cfg.DokployAPIKey != "" || cfg.DokployToken != "" || cfg.DokploySession != ""
-	if !dokployAuth {
-		slog.Warn("⚠️  No Dokploy authentication configured - API calls may fail")
+	if cfg.PangolinURL == "" {
+		return fmt.Errorf("PANGOLIN_URL is required")
 	}
 
 	pangolinAuth := cfg.PangolinToken != "" || cfg.PangolinAPIKey != ""
@@ -622,24 +797,42 @@ func validateConfig(cfg *Config) error {
 			return fmt.Errorf("POLL_INTERVAL must be at least 5 seconds")
 		}
 	}
-	
+
 	if cfg.RetryAttempts < 1 || cfg.RetryAttempts > 10 {
 		return fmt.Errorf("RETRY_ATTEMPTS must be between 1 and 10")
 	}
 
+	if cfg.StatePath == "" {
+		return fmt.Errorf("STATE_PATH must not be empty")
+	}
+
+	switch cfg.LBStrategy {
+	case pangolin.LBRandom, pangolin.LBRoundRobin, pangolin.LBFailover, pangolin.LBIPHash:
+	default:
+		return fmt.Errorf("LB_STRATEGY must be one of random, roundrobin, failover, iphash")
+	}
+
 	// Log configuration for transparency
-	slog.Info("Configuration loaded", 
+	slog.Info("Configuration loaded",
 		"dokploy_url", cfg.DokployURL,
 		"pangolin_url", cfg.PangolinURL,
 		"poll_interval", cfg.PollInterval,
 		"retry_attempts", cfg.RetryAttempts,
 		"run_once", cfg.RunOnce,
-		"force_sync", cfg.ForceSync)
+		"force_sync", cfg.ForceSync,
+		"discovery_providers", cfg.DiscoveryProviders,
+		"state_path", cfg.StatePath,
+		"dry_run", cfg.DryRun,
+		"lb_strategy", cfg.LBStrategy)
 
 	return nil
 }
 
-func startHealthServer() {
+func startHealthServer(d *DockOtter) {
+	if webhookHandler := d.webhookHandler(); webhookHandler != nil {
+		http.Handle("/webhook/dokploy", webhookHandler)
+	}
+
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -654,11 +847,7 @@ func startHealthServer() {
 		json.NewEncoder(w).Encode(response)
 	})
 
-	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "text/plain")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("# Basic metrics endpoint\ndock_otter_up 1\n"))
-	})
+	http.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
 
 	slog.Info("🏥 Health check server starting on :8080")
 	if err := http.ListenAndServe(":8080", nil); err != nil {
@@ -701,6 +890,27 @@ func getBoolEnv(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getListEnv reads a comma-separated list, trimming whitespace around each
+// entry and dropping empty ones.
+func getListEnv(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			result = append(result, part)
+		}
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
+
 func printUsage() {
 	fmt.Printf(`🦦 Dock Otter - Dokploy to Pangolin Bridge
 
@@ -710,16 +920,32 @@ Usage:
 Flags:
   --version, -v     Show version information
   --health-check    Check if service is healthy (for Docker)
+  --dry-run         Log the create/update/delete plan without changing Pangolin
   --help, -h        Show this help message
 
 Environment Variables:
-  DOKPLOY_URL       Dokploy API endpoint (default: http://dokploy:3000)
-  DOKPLOY_API_KEY   Dokploy API key
-  PANGOLIN_URL      Pangolin API endpoint (default: http://pangolin:3001)
-  PANGOLIN_TOKEN    Pangolin Bearer token (required)
-  POLL_INTERVAL     Sync interval (default: 30s)
-  RUN_ONCE          Run once and exit (default: false)
-  FORCE_SYNC        Force re-sync existing resources (default: false)
+  DOKPLOY_URL          Dokploy API endpoint (default: http://dokploy:3000)
+  DOKPLOY_API_KEY      Dokploy API key
+  PANGOLIN_URL         Pangolin API endpoint (default: http://pangolin:3001)
+  PANGOLIN_TOKEN       Pangolin Bearer token (required unless PANGOLIN_API_KEY is set)
+  POLL_INTERVAL        Sync interval (default: 30s)
+  RUN_ONCE             Run once and exit (default: false)
+  FORCE_SYNC           Force re-sync existing resources (default: false)
+  DISCOVERY_PROVIDERS  Comma-separated providers to enable (default: dokploy)
+  DOCKER_LABEL_PREFIX  Label prefix for the docker provider (default: dockotter)
+  STATIC_SERVICES_FILE Path to the YAML file for the file provider
+  CONSUL_ADDR          Consul agent address for the consul provider
+  STATE_PATH           Where the reconcile state file is kept (default: ./dock-otter-state.json)
+  DRY_RUN              Log the reconcile plan without changing Pangolin (default: false)
+  DOKPLOY_WEBHOOK_SECRET  Enables POST /webhook/dokploy, verified via HMAC-SHA256
+  DOCKER_EVENTS_DEBOUNCE  Coalescing window for Docker event bursts (default: 2s)
+  LB_STRATEGY             Load-balancer strategy for multi-backend services: random, roundrobin, failover, iphash (default: random)
+  HEALTH_CHECK_PATH       Default active health-check path for failover strategy
+  HEALTH_CHECK_INTERVAL   Default active health-check interval (default: 10s)
+  MIDDLEWARE_CONFIG       Path to a YAML file's "middlewares:" section (headers, basic auth, path rewrites, rate limits, IP filtering)
+  BASIC_HTPASSWD          Wrap every resource in HTTP basic auth against this htpasswd file
+  PLUGIN_ADDR             Unix socket address of an out-of-process net/rpc blueprint transform plugin
+  OTEL_EXPORTER_OTLP_ENDPOINT  OTLP/gRPC collector endpoint for tracing (tracing disabled if unset)
 
 Examples:
   # Run as daemon
@@ -731,6 +957,12 @@ Examples:
   # Force sync existing apps
   RUN_ONCE=true FORCE_SYNC=true dock-otter
 
+  # Sync from Dokploy and plain Docker containers
+  DISCOVERY_PROVIDERS=dokploy,docker dock-otter
+
+  # See what would change without touching Pangolin
+  dock-otter --dry-run
+
 Version: %s
 `, version)
-}
\ No newline at end of file
+}
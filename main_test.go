@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/Gadgitmatic/Dock-Otter/internal/discovery"
+)
+
+// stubProvider is a discovery.Provider backed by a canned service list, used
+// to drive buildDesiredResources without a real Dokploy/Docker/Consul
+// backend.
+type stubProvider struct {
+	name     string
+	services []discovery.Service
+}
+
+func (s stubProvider) Name() string { return s.name }
+func (s stubProvider) List(context.Context) ([]discovery.Service, error) {
+	return s.services, nil
+}
+
+// TestBuildDesiredResources_MultiDomainService verifies that a service with
+// several domains - what dokploy.Provider.List produces for an app with
+// more than one domain - yields one Pangolin resource per domain instead of
+// the merge step collapsing them down to one, which would otherwise make
+// the prune pass delete the dropped domains' resources on the next sync.
+func TestBuildDesiredResources_MultiDomainService(t *testing.T) {
+	d := &DockOtter{
+		config: &Config{},
+		providers: []discovery.Provider{
+			stubProvider{name: "dokploy", services: []discovery.Service{
+				{
+					Name:     "web",
+					Domains:  []string{"example.com", "www.example.com"},
+					Backends: []discovery.Backend{{Hostname: "web-app", Port: 3000, Weight: 1, Priority: 1}},
+				},
+			}},
+		},
+	}
+
+	desired, order, err := d.buildDesiredResources(context.Background())
+	if err != nil {
+		t.Fatalf("buildDesiredResources() error = %v", err)
+	}
+
+	if len(desired) != 2 {
+		t.Fatalf("len(desired) = %d, want 2 (one resource per domain): %v", len(desired), order)
+	}
+
+	gotDomains := make([]string, 0, len(desired))
+	for _, resourceName := range order {
+		gotDomains = append(gotDomains, desired[resourceName].FullDomain)
+	}
+	sort.Strings(gotDomains)
+	wantDomains := []string{"example.com", "www.example.com"}
+	for i, want := range wantDomains {
+		if gotDomains[i] != want {
+			t.Errorf("domains = %v, want %v", gotDomains, wantDomains)
+		}
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	a := contentHash([]byte("hello"))
+	b := contentHash([]byte("hello"))
+	c := contentHash([]byte("world"))
+
+	if a != b {
+		t.Errorf("contentHash not deterministic: %q != %q", a, b)
+	}
+	if a == c {
+		t.Errorf("contentHash collided for different inputs: %q", a)
+	}
+	if len(a) != 64 {
+		t.Errorf("contentHash length = %d, want 64 (hex sha256)", len(a))
+	}
+}
+
+func TestDecideReconcileAction(t *testing.T) {
+	tests := []struct {
+		name         string
+		existsRemote bool
+		knownHash    bool
+		lastHash     string
+		hash         string
+		force        bool
+		want         reconcileAction
+	}{
+		{"unchanged resource is skipped", true, true, "abc", "abc", false, actionSkip},
+		{"force re-applies an otherwise unchanged resource", true, true, "abc", "abc", true, actionUpdate},
+		{"changed hash triggers update", true, true, "abc", "def", false, actionUpdate},
+		{"missing from Pangolin triggers create even if hash known", false, true, "abc", "abc", false, actionCreate},
+		{"never persisted locally triggers update, not skip", true, false, "", "abc", false, actionUpdate},
+		{"brand new resource is created", false, false, "", "abc", false, actionCreate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decideReconcileAction(tt.existsRemote, tt.knownHash, tt.lastHash, tt.hash, tt.force)
+			if got != tt.want {
+				t.Errorf("decideReconcileAction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}